@@ -0,0 +1,16 @@
+# This Source Code Form is subject to the terms of the Mozilla Public
+# License, v. 2.0. If a copy of the MPL was not distributed with this
+# file, You can obtain one at https://mozilla.org/MPL/2.0/.
+#
+# SPDX-License-Identifier: MPL-2.0
+
+data "observability_objects" "aws_connections" {
+  type_name   = "anzen:cloudConnection"
+  layer_type  = "TENANT"
+  layer_id    = "0eb4e853-34fb-4f77-b3fc-b9cd3b462366"
+  filters     = ["data.cloudType eq \"AWS\""]
+  sort_by     = "connectionName"
+  sort_order  = "asc"
+  max_results = 10
+  fields      = ["connectionName", "region"]
+}
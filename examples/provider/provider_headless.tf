@@ -0,0 +1,21 @@
+# This Source Code Form is subject to the terms of the Mozilla Public
+# License, v. 2.0. If a copy of the MPL was not distributed with this
+# file, You can obtain one at https://mozilla.org/MPL/2.0/.
+#
+# SPDX-License-Identifier: MPL-2.0
+
+terraform {
+  required_providers {
+    observability = {
+      source = "registry.terraform.io/cisco-open/observability"
+    }
+  }
+}
+
+provider "observability" {
+  tenant      = "<your cisco observability account>"
+  auth_method = "headless"
+  url         = "https://<your environment/host>"
+  username    = "<your username>"
+  password    = "<your password>"
+}
@@ -0,0 +1,40 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+//
+// SPDX-License-Identifier: MPL-2.0
+
+package api
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/apex/log"
+)
+
+// ErrTokenExpired is returned when the access token handed to the client (access-token authentication
+// method) has expired; a new one has to be minted since it cannot be refreshed
+var ErrTokenExpired = errors.New("the access token has expired")
+
+// accessTokenLogin validates the access token set on the client instead of authenticating; the caller
+// must hold tokenMu
+func (ac *AppdClient) accessTokenLogin() error {
+	if ac.Token == "" {
+		return errors.New("no access token provided")
+	}
+
+	claims, err := parseJWTClaims(ac.Token)
+	if err != nil {
+		// opaque tokens are passed through as is, the platform is the judge of their validity
+		log.Warnf("Unable to check the access token expiry: %v", err)
+		return nil
+	}
+
+	ac.tokenExpiry = claims.expiresAt()
+	if !ac.tokenExpiry.IsZero() && time.Now().After(ac.tokenExpiry) {
+		return fmt.Errorf("%w at %v", ErrTokenExpired, ac.tokenExpiry.Format(time.RFC3339))
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+//
+// SPDX-License-Identifier: MPL-2.0
+
+//go:build unit
+
+package api_test
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/cisco-open/terraform-provider-observability/internal/api"
+)
+
+// newJWT builds an unsigned JWT carrying the given JSON claims
+func newJWT(claims string) string {
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg": "RS256", "typ": "JWT"}`))
+	return header + "." + base64.RawURLEncoding.EncodeToString([]byte(claims)) + ".signature"
+}
+
+func TestAccessTokenLogin(t *testing.T) {
+	valid := newJWT(fmt.Sprintf(`{"sub": "ci", "exp": %d}`, time.Now().Add(time.Hour).Unix()))
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+valid {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, expectedResponse)
+	}))
+	defer srv.Close()
+
+	ac := &api.AppdClient{
+		URL:        srv.URL,
+		Tenant:     tenant,
+		AuthMethod: "access-token",
+		Token:      valid,
+		APIClient:  srv.Client(),
+	}
+
+	if err := ac.Login(context.Background()); err != nil {
+		t.Fatalf("Login returned an error: %v", err)
+	}
+	response, err := ac.GetType(context.Background(), testType)
+	if err != nil {
+		t.Fatalf("GetType returned an error: %v", err)
+	}
+	if string(response) != expectedResponse {
+		t.Errorf("GetType returned %s, expected %s", response, expectedResponse)
+	}
+}
+
+func TestAccessTokenExpired(t *testing.T) {
+	tests := []struct {
+		name    string
+		token   string
+		expired bool
+	}{
+		{"expired", newJWT(fmt.Sprintf(`{"exp": %d}`, time.Now().Add(-time.Minute).Unix())), true},
+		{"no expiry", newJWT(`{"sub": "ci"}`), false},
+		{"opaque", "opaque-token", false},
+	}
+
+	for _, test := range tests {
+		ac := &api.AppdClient{
+			URL:        "http://127.0.0.1:0",
+			Tenant:     tenant,
+			AuthMethod: "access-token",
+			Token:      test.token,
+			APIClient:  http.DefaultClient,
+		}
+
+		err := ac.Login(context.Background())
+		if errors.Is(err, api.ErrTokenExpired) != test.expired {
+			t.Errorf("%s: Login returned %v", test.name, err)
+		}
+	}
+}
+
+func TestTokenInfo(t *testing.T) {
+	expiry := time.Now().Add(time.Hour).Unix()
+	tests := []struct {
+		name   string
+		claims string
+		tenant string
+	}{
+		{"tenant", fmt.Sprintf(`{"sub": "principal-1", "tenant": %q, "exp": %d}`, tenant, expiry), tenant},
+		{"tenantId", fmt.Sprintf(`{"sub": "principal-1", "tenantId": %q, "exp": %d}`, tenant, expiry), tenant},
+		{"tenant_id", fmt.Sprintf(`{"sub": "principal-1", "tenant_id": %q, "exp": %d}`, tenant, expiry), tenant},
+		{"no tenant", fmt.Sprintf(`{"sub": "principal-1", "exp": %d}`, expiry), ""},
+	}
+
+	for _, test := range tests {
+		ac := &api.AppdClient{Token: newJWT(test.claims)}
+		info, err := ac.TokenInfo()
+		if err != nil {
+			t.Errorf("%s: TokenInfo returned an error: %v", test.name, err)
+			continue
+		}
+		if info.Principal != "principal-1" || info.Tenant != test.tenant || info.ExpiresAt.Unix() != expiry {
+			t.Errorf("%s: unexpected token info %+v", test.name, info)
+		}
+	}
+}
+
+func TestTokenInfoOpaqueToken(t *testing.T) {
+	if _, err := (&api.AppdClient{}).TokenInfo(); err == nil {
+		t.Errorf("TokenInfo returned no error without a token")
+	}
+	if _, err := (&api.AppdClient{Token: token}).TokenInfo(); err == nil {
+		t.Errorf("TokenInfo returned no error for an opaque token")
+	}
+}
@@ -0,0 +1,67 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+//
+// SPDX-License-Identifier: MPL-2.0
+
+package api
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// agentCredentials is the agent principal credentials file downloaded from the platform when
+// configuring agents and collectors (YAML, or JSON which is parsed the same way)
+type agentCredentials struct {
+	ClientID     string `yaml:"clientId"`
+	ClientSecret string `yaml:"clientSecret"`
+	TokenURL     string `yaml:"tokenUrl"`
+	TenantID     string `yaml:"tenantId"`
+}
+
+func (ac *AppdClient) agentPrincipalLogin(ctx context.Context) error {
+	// read credentials file
+	file := ac.SecretFile
+	credentials, err := readAgentCredentials(file)
+	if err != nil {
+		return err
+	}
+
+	// the credentials only work for the tenant they were issued for
+	if credentials.TenantID != "" && ac.Tenant != "" && !strings.EqualFold(credentials.TenantID, ac.Tenant) {
+		return fmt.Errorf("the agent credentials in %q were issued for tenant %q, not %q", file, credentials.TenantID, ac.Tenant)
+	}
+	if ac.Tenant == "" {
+		ac.Tenant = credentials.TenantID
+	}
+
+	// use the token endpoint from the credentials, falling back to the tenant's one
+	tokenURI := credentials.TokenURL
+	if tokenURI == "" {
+		tokenURI = oauthURIWithSuffix(ac, oauth2TokenURISuffix)
+	}
+
+	return clientCredentialsLogin(ctx, ac, tokenURI, credentials.ClientID, credentials.ClientSecret)
+}
+
+func readAgentCredentials(file string) (*agentCredentials, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the agent credentials file %q: %w", file, err)
+	}
+
+	var credentials agentCredentials
+	if err = yaml.Unmarshal(data, &credentials); err != nil {
+		return nil, fmt.Errorf("failed to parse agent credentials file %q: %w", file, err)
+	}
+	if credentials.ClientID == "" || credentials.ClientSecret == "" {
+		return nil, fmt.Errorf("the agent credentials file %q must contain clientId and clientSecret", file)
+	}
+
+	return &credentials, nil
+}
@@ -6,11 +6,17 @@
 
 package api
 
+import "time"
+
 // authentication types
 const (
-	authMethodOAuth  = "oauth"
-	headless         = "headless"
-	servicePrincipal = "service-principal"
+	authMethodOAuth       = "oauth"
+	headless              = "headless"
+	servicePrincipal      = "service-principal"
+	agentPrincipal        = "agent-principal"
+	authMethodAccessToken = "access-token"
+	authMethodDeviceCode  = "oauth-device"
+	credentialProcess     = "credential-process"
 	// TODO add new types of authentication method here...
 )
 
@@ -20,8 +26,14 @@ const (
 	oauth2AuthURISuffix = "oauth2/authorize" // API for obtaining authorization codes
 	//nolint:gosec // This is not a hard coded secret
 	oauth2TokenURISuffix = "oauth2/token" // API for exchanging the auth code for a token
-	oauthRedirectURI     = "http://127.0.0.1:3101/callback"
-	SHA256Hash           = "S256" // the SHA-256 hashing alorithm used to generate the code challenge for PKCE
+	oauthCallbackPath    = "/callback"    // path of the redirect URI served by the local callback server
+	SHA256Hash           = "S256"         // the SHA-256 hashing alorithm used to generate the code challenge for PKCE
+)
+
+// OAuth login defaults
+const (
+	DefaultOAuthCallbackAddr = "127.0.0.1:3101" // local address receiving the OAuth callback, see AppdClient.CallbackAddr
+	DefaultLoginTimeout      = 5 * time.Minute  // how long the user has to complete the login in the browser
 )
 
 const (
@@ -0,0 +1,90 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+//
+// SPDX-License-Identifier: MPL-2.0
+
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/apex/log"
+)
+
+// credentialProcessTimeout bounds how long the credential process may run, it may prompt the user
+const credentialProcessTimeout = 2 * time.Minute
+
+// credentialProcessOutput is the JSON document the credential process prints on its stdout
+type credentialProcessOutput struct {
+	AccessToken string    `json:"access_token"`
+	ExpiresAt   time.Time `json:"expires_at"` // RFC 3339, optional
+	ExpiresIn   int       `json:"expires_in"` // seconds, optional alternative to expires_at
+}
+
+// credentialProcessLogin runs the CredentialProcess command to obtain an access token, e.g. from a
+// credential broker; the caller must hold tokenMu. The token is used until shortly before it expires,
+// then the command is run again.
+func (ac *AppdClient) credentialProcessLogin(ctx context.Context) error {
+	if ac.CredentialProcess == "" {
+		return errors.New("no credential process configured")
+	}
+	log.Infof("Obtaining an access token from the credential process")
+
+	ctx, cancel := context.WithTimeout(ctx, credentialProcessTimeout)
+	defer cancel()
+
+	// run through the shell so that the command can be configured as a single string with arguments
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd.exe", "/C", ac.CredentialProcess)
+	} else {
+		cmd = exec.CommandContext(ctx, "/bin/sh", "-c", ac.CredentialProcess)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		msg := truncate(strings.TrimSpace(stderr.String()), maxErrorMessageLen)
+		if msg == "" {
+			return fmt.Errorf("the credential process failed: %w", err)
+		}
+		return fmt.Errorf("the credential process failed: %w: %s", err, msg)
+	}
+
+	var output credentialProcessOutput
+	if err := json.Unmarshal(stdout.Bytes(), &output); err != nil {
+		return fmt.Errorf("failed to parse the credential process output as JSON: %w", err)
+	}
+	if output.AccessToken == "" {
+		return errors.New("the credential process output has no access_token")
+	}
+
+	// take the expiry from the output, falling back to the token itself
+	expiry := output.ExpiresAt
+	switch {
+	case !expiry.IsZero():
+	case output.ExpiresIn > 0:
+		expiry = time.Now().Add(time.Duration(output.ExpiresIn) * time.Second)
+	default:
+		if claims, err := parseJWTClaims(output.AccessToken); err == nil {
+			expiry = claims.expiresAt()
+		}
+	}
+	if !expiry.IsZero() && time.Now().After(expiry) {
+		return fmt.Errorf("the credential process returned an access token which expired at %v", expiry.Format(time.RFC3339))
+	}
+
+	ac.Token = output.AccessToken
+	ac.tokenExpiry = expiry
+	return nil
+}
@@ -0,0 +1,135 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+//
+// SPDX-License-Identifier: MPL-2.0
+
+//go:build unit
+
+package api_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cisco-open/terraform-provider-observability/internal/api"
+)
+
+const credentialProcess = "credential-process"
+
+// newCredentialProcess writes a script printing the given output and counting its runs in a file
+func newCredentialProcess(t *testing.T, output string) (command, runsFile string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	runsFile = filepath.Join(dir, "runs")
+	script := filepath.Join(dir, "helper.sh")
+	contents := fmt.Sprintf("#!/bin/sh\necho run >> %q\ncat <<'EOF'\n%s\nEOF\n", runsFile, output)
+	if err := os.WriteFile(script, []byte(contents), 0o700); err != nil {
+		t.Fatalf("Failed to write the credential process: %v", err)
+	}
+	return script, runsFile
+}
+
+func credentialProcessRuns(t *testing.T, runsFile string) int {
+	t.Helper()
+
+	data, err := os.ReadFile(runsFile)
+	if err != nil {
+		return 0
+	}
+	return strings.Count(string(data), "run")
+}
+
+func TestCredentialProcessLogin(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"token": %q}`, strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "))
+	}))
+	defer srv.Close()
+
+	expiresAt := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	command, runsFile := newCredentialProcess(t, fmt.Sprintf(`{"access_token": %q, "expires_at": %q}`, token, expiresAt))
+
+	ac := &api.AppdClient{
+		URL:               srv.URL,
+		Tenant:            tenant,
+		AuthMethod:        credentialProcess,
+		CredentialProcess: command,
+		APIClient:         srv.Client(),
+	}
+	if err := ac.Login(context.Background()); err != nil {
+		t.Fatalf("Login returned an error: %v", err)
+	}
+	if ac.Token != token {
+		t.Errorf("Login set the access token %q, expected %q", ac.Token, token)
+	}
+
+	// the token is cached in the client until it is about to expire
+	if _, err := ac.GetType(context.Background(), "sample:type"); err != nil {
+		t.Fatalf("GetType returned an error: %v", err)
+	}
+	if runs := credentialProcessRuns(t, runsFile); runs != 1 {
+		t.Errorf("The credential process ran %d times, expected once", runs)
+	}
+}
+
+func TestCredentialProcessRefresh(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"token": %q}`, strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "))
+	}))
+	defer srv.Close()
+
+	// expires within the expiry skew, so the process runs again before the next request
+	command, runsFile := newCredentialProcess(t, fmt.Sprintf(`{"access_token": %q, "expires_in": 30}`, token))
+
+	ac := &api.AppdClient{
+		URL:               srv.URL,
+		Tenant:            tenant,
+		AuthMethod:        credentialProcess,
+		CredentialProcess: command,
+		APIClient:         srv.Client(),
+	}
+	if err := ac.Login(context.Background()); err != nil {
+		t.Fatalf("Login returned an error: %v", err)
+	}
+	if _, err := ac.GetType(context.Background(), "sample:type"); err != nil {
+		t.Fatalf("GetType returned an error: %v", err)
+	}
+	if runs := credentialProcessRuns(t, runsFile); runs != 2 {
+		t.Errorf("The credential process ran %d times, expected twice", runs)
+	}
+}
+
+func TestCredentialProcessErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		command  string
+		expected string
+	}{
+		{"failure", "echo 'not logged in to the broker' >&2; exit 3", "not logged in to the broker"},
+		{"invalid output", "echo not-json", "JSON"},
+		{"no token", `echo '{"expires_in": 3600}'`, "no access_token"},
+		{"expired", `echo '{"access_token": "t", "expires_at": "2000-01-01T00:00:00Z"}'`, "expired"},
+	}
+
+	for _, test := range tests {
+		ac := &api.AppdClient{
+			URL:               "http://127.0.0.1:0",
+			Tenant:            tenant,
+			AuthMethod:        credentialProcess,
+			CredentialProcess: test.command,
+			APIClient:         http.DefaultClient,
+		}
+		err := ac.Login(context.Background())
+		if err == nil || !strings.Contains(err.Error(), test.expected) {
+			t.Errorf("%s: Login returned %v, expected an error containing %q", test.name, err, test.expected)
+		}
+	}
+}
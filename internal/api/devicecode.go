@@ -0,0 +1,197 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+//
+// SPDX-License-Identifier: MPL-2.0
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/apex/log"
+)
+
+// device authorization grant (RFC 8628) related data
+const (
+	oauth2DeviceAuthURISuffix = "oauth2/device/auth" // API for obtaining the device and user codes
+	deviceCodeGrantType       = "urn:ietf:params:oauth:grant-type:device_code"
+	deviceDefaultInterval     = 5 * time.Second // polling interval when the platform does not specify one
+	deviceSlowDownIncrement   = 5 * time.Second // added to the polling interval when asked to slow down
+)
+
+// DeviceAuthorization describes what the user has to do to approve a device code login:
+// open VerificationURI in any browser (e.g. on their laptop) and enter UserCode
+type DeviceAuthorization struct {
+	VerificationURI         string
+	VerificationURIComplete string // VerificationURI with the user code already filled in, if the platform provides it
+	UserCode                string
+	ExpiresAt               time.Time
+}
+
+// deviceAuthResponse is what the device authorization endpoint returns
+type deviceAuthResponse struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete"`
+	ExpiresIn               int    `json:"expires_in"`
+	Interval                int    `json:"interval"`
+}
+
+// deviceCodeLogin authenticates with the OAuth device authorization grant: the user approves the login
+// in a browser on any other machine while the client polls the token endpoint, so neither a local
+// browser nor a local callback server are needed
+func (ac *AppdClient) deviceCodeLogin(ctx context.Context) error {
+	log.Infof("Starting OAuth device authorization flow")
+
+	auth, err := ac.requestDeviceAuthorization(ctx)
+	if err != nil {
+		return fmt.Errorf("login failed to obtain a device code: %w", err)
+	}
+
+	expiresAt := time.Now().Add(time.Duration(auth.ExpiresIn) * time.Second)
+	prompt := &DeviceAuthorization{
+		VerificationURI:         auth.VerificationURI,
+		VerificationURIComplete: auth.VerificationURIComplete,
+		UserCode:                auth.UserCode,
+		ExpiresAt:               expiresAt,
+	}
+	if ac.DevicePrompt != nil {
+		ac.DevicePrompt(prompt)
+	} else {
+		log.Warnf("To log in, visit %s and enter the code %s", prompt.VerificationURI, prompt.UserCode)
+	}
+
+	interval := time.Duration(auth.Interval) * time.Second
+	if interval <= 0 {
+		interval = deviceDefaultInterval
+	}
+
+	// poll until the user approved or denied the login, or the device code expired
+	for {
+		if auth.ExpiresIn > 0 && time.Now().After(expiresAt) {
+			return fmt.Errorf("login failed: the device code expired before the login was approved at %s", prompt.VerificationURI)
+		}
+		if err = sleepContext(ctx, interval); err != nil {
+			return fmt.Errorf("login aborted while waiting for the approval at %s: %w", prompt.VerificationURI, err)
+		}
+
+		token, pollErr := ac.pollDeviceToken(ctx, auth.DeviceCode)
+		switch {
+		case pollErr == nil:
+			ac.setTokens(token)
+			return nil
+		case errors.Is(pollErr, errAuthorizationPending):
+			continue
+		case errors.Is(pollErr, errSlowDown):
+			interval += deviceSlowDownIncrement
+		default:
+			return fmt.Errorf("login failed: %w", pollErr)
+		}
+	}
+}
+
+// polling outcomes that are not failures
+var (
+	errAuthorizationPending = errors.New("authorization pending")
+	errSlowDown             = errors.New("slow down")
+)
+
+func (ac *AppdClient) requestDeviceAuthorization(ctx context.Context) (*deviceAuthResponse, error) {
+	values := url.Values{}
+	values.Add("client_id", oauth2ClientID)
+	values.Add("scope", "openid introspect_tokens offline_access")
+
+	respBytes, err := ac.postTokenForm(ctx, oauthURIWithSuffix(ac, oauth2DeviceAuthURISuffix), values)
+	if err != nil {
+		return nil, err
+	}
+
+	var auth deviceAuthResponse
+	if err = json.Unmarshal(respBytes, &auth); err != nil {
+		return nil, fmt.Errorf("failed to JSON parse the device authorization response: %w", err)
+	}
+	if auth.DeviceCode == "" || auth.UserCode == "" || auth.VerificationURI == "" {
+		return nil, errors.New("the device authorization response is incomplete")
+	}
+	return &auth, nil
+}
+
+// pollDeviceToken asks the token endpoint whether the user approved the login yet
+func (ac *AppdClient) pollDeviceToken(ctx context.Context, deviceCode string) (*appTokens, error) {
+	values := url.Values{}
+	values.Add("client_id", oauth2ClientID)
+	values.Add("grant_type", deviceCodeGrantType)
+	values.Add("device_code", deviceCode)
+
+	respBytes, err := ac.postTokenForm(ctx, oauthURIWithSuffix(ac, oauth2TokenURISuffix), values)
+	var endpointErr *oauthEndpointError
+	if errors.As(err, &endpointErr) {
+		switch endpointErr.payload.Error {
+		case "authorization_pending":
+			return nil, errAuthorizationPending
+		case "slow_down":
+			return nil, errSlowDown
+		case "access_denied":
+			return nil, errors.New("the login was denied")
+		case "expired_token":
+			return nil, errors.New("the device code expired before the login was approved")
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var token appTokens
+	if err = json.Unmarshal(respBytes, &token); err != nil {
+		return nil, fmt.Errorf("failed to JSON parse the response as a token object: %w", err)
+	}
+	return &token, nil
+}
+
+// oauthEndpointError is a failed response from the auth/token endpoints, keeping the OAuth error details
+type oauthEndpointError struct {
+	payload oauthErrorPayload
+	err     error
+}
+
+func (e *oauthEndpointError) Error() string {
+	return e.err.Error()
+}
+
+// postTokenForm posts the url-encoded values to an auth/token endpoint and returns the response body,
+// failures are reported as *oauthEndpointError
+func (ac *AppdClient) postTokenForm(ctx context.Context, uri string, values url.Values) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, uri, strings.NewReader(values.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create a request for %q: %w", uri, err)
+	}
+	req.Header.Add("Accept", jsonContentType)
+	req.Header.Add("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+
+	resp, err := ac.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("POST request to %q failed: %w", uri, err)
+	}
+	defer resp.Body.Close()
+
+	respBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed reading response to POST to %q: %w", uri, err)
+	}
+	if resp.StatusCode/100 != 2 {
+		endpointErr := &oauthEndpointError{err: tokenEndpointError(resp, respBytes)}
+		_ = json.Unmarshal(respBytes, &endpointErr.payload) // best effort, err already covers non-JSON bodies
+		return nil, endpointErr
+	}
+	return respBytes, nil
+}
@@ -0,0 +1,121 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+//
+// SPDX-License-Identifier: MPL-2.0
+
+//go:build unit
+
+package api_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cisco-open/terraform-provider-observability/internal/api"
+)
+
+const (
+	deviceAuthPath = "/auth/" + tenant + "/default/oauth2/device/auth"
+	deviceCode     = "device-code"
+	userCode       = "ABCD-EFGH"
+)
+
+// newDeviceServer mocks the device authorization and token endpoints, answering the token polls
+// with the given OAuth errors in turn before issuing a token
+func newDeviceServer(t *testing.T, pollErrors ...string) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+
+	var polls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("Failed to parse the form: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.URL.Path {
+		case deviceAuthPath:
+			fmt.Fprintf(w, `{"device_code": %q, "user_code": %q, "verification_uri": "https://verify.example.com",
+				"expires_in": 600, "interval": 1}`, deviceCode, userCode)
+		case tokenPath:
+			if r.Form.Get("device_code") != deviceCode {
+				t.Errorf("Expected device code %q, got %q", deviceCode, r.Form.Get("device_code"))
+			}
+			n := int(polls.Add(1))
+			if n <= len(pollErrors) {
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprintf(w, `{"error": %q}`, pollErrors[n-1])
+				return
+			}
+			fmt.Fprint(w, `{"access_token": "device-token", "refresh_token": "device-refresh", "expires_in": 3600}`)
+		default:
+			t.Errorf("Unexpected request to %s", r.URL.Path)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &polls
+}
+
+func newDeviceClient(srv *httptest.Server, prompted *api.DeviceAuthorization) *api.AppdClient {
+	return &api.AppdClient{
+		AuthMethod: "oauth-device",
+		URL:        srv.URL,
+		Tenant:     tenant,
+		APIClient:  srv.Client(),
+		DevicePrompt: func(auth *api.DeviceAuthorization) {
+			*prompted = *auth
+		},
+	}
+}
+
+func TestDeviceCodeLogin(t *testing.T) {
+	srv, polls := newDeviceServer(t, "authorization_pending")
+
+	var prompted api.DeviceAuthorization
+	client := newDeviceClient(srv, &prompted)
+	if err := client.Login(context.Background()); err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+
+	if prompted.UserCode != userCode || prompted.VerificationURI != "https://verify.example.com" {
+		t.Errorf("Unexpected prompt %+v", prompted)
+	}
+	if got := polls.Load(); got != 2 {
+		t.Errorf("Expected 2 token polls, got %d", got)
+	}
+	if client.Token != "device-token" || client.RefreshToken != "device-refresh" {
+		t.Errorf("Unexpected tokens %q and %q", client.Token, client.RefreshToken)
+	}
+}
+
+func TestDeviceCodeLoginDenied(t *testing.T) {
+	srv, _ := newDeviceServer(t, "access_denied")
+
+	var prompted api.DeviceAuthorization
+	err := newDeviceClient(srv, &prompted).Login(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "denied") {
+		t.Fatalf("Expected the login to be denied, got %v", err)
+	}
+}
+
+func TestDeviceCodeLoginCanceled(t *testing.T) {
+	srv, polls := newDeviceServer(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	var prompted api.DeviceAuthorization
+	err := newDeviceClient(srv, &prompted).Login(ctx)
+	if err == nil || !strings.Contains(err.Error(), "aborted") {
+		t.Fatalf("Expected the login to be aborted, got %v", err)
+	}
+	if got := polls.Load(); got != 0 {
+		t.Errorf("Expected no token polls, got %d", got)
+	}
+}
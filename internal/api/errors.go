@@ -0,0 +1,156 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+//
+// SPDX-License-Identifier: MPL-2.0
+
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// maxErrorMessageLen caps how much of a non-JSON error body ends up in the error message
+const maxErrorMessageLen = 512
+
+// requestIDHeaders lists the response headers that carry the platform request/trace ID, in order of preference
+var requestIDHeaders = []string{"X-Request-Id", "X-Trace-Id", "X-B3-Traceid"}
+
+// APIError is returned by the AppdClient methods when the platform responds with a non-2xx status
+type APIError struct {
+	Method     string // HTTP method of the failed request
+	URL        string // URL of the failed request
+	StatusCode int    // HTTP status code, e.g., 404
+	Code       string // platform error code, if the payload carries one
+	Message    string // human readable error message, if the payload carries one
+	RequestID  string // request/trace ID reported by the platform, useful when contacting support
+	Retryable  bool   // whether the request may succeed if issued again
+}
+
+// errorPayload is the structure returned by the knowledge store on 4xx/5xx errors (RFC 7807 problem details
+// with a few platform specific extensions)
+type errorPayload struct {
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Detail    string `json:"detail"`
+	Message   string `json:"message"`
+	ErrorCode string `json:"errorCode"`
+	Code      string `json:"code"`
+	Error     string `json:"error"`
+	ErrorDesc string `json:"error_description"`
+}
+
+func (e *APIError) Error() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%v request to %q failed with status %d", e.Method, e.URL, e.StatusCode)
+	if e.Code != "" {
+		fmt.Fprintf(&sb, " (error code %q)", e.Code)
+	}
+	if e.Message != "" {
+		fmt.Fprintf(&sb, ": %s", e.Message)
+	}
+	if e.RequestID != "" {
+		fmt.Fprintf(&sb, " [request ID %s]", e.RequestID)
+	}
+	return sb.String()
+}
+
+// newAPIError builds an APIError out of a failed response and its already read body
+func newAPIError(resp *http.Response, body []byte) *APIError {
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode,
+		Retryable:  isRetryableStatus(resp.StatusCode),
+	}
+	if resp.Request != nil {
+		apiErr.Method = resp.Request.Method
+		apiErr.URL = resp.Request.URL.String()
+	}
+	for _, h := range requestIDHeaders {
+		if id := resp.Header.Get(h); id != "" {
+			apiErr.RequestID = id
+			break
+		}
+	}
+
+	// parse the payload (tolerate non-JSON responses)
+	var payload errorPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		apiErr.Message = truncate(strings.TrimSpace(string(body)), maxErrorMessageLen)
+		if apiErr.Message == "" {
+			apiErr.Message = http.StatusText(resp.StatusCode)
+		}
+		return apiErr
+	}
+
+	apiErr.Code = firstNonEmpty(payload.ErrorCode, payload.Code, payload.Error, payload.Type)
+	apiErr.Message = firstNonEmpty(payload.Detail, payload.Message, payload.ErrorDesc, payload.Title,
+		http.StatusText(resp.StatusCode))
+	return apiErr
+}
+
+// isRetryableStatus reports whether a response status indicates a transient failure
+func isRetryableStatus(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsNotFound reports whether err is an APIError caused by a missing type or object
+func IsNotFound(err error) bool {
+	return hasStatus(err, http.StatusNotFound)
+}
+
+// IsConflict reports whether err is an APIError caused by a conflicting object (e.g., one that already exists)
+func IsConflict(err error) bool {
+	return hasStatus(err, http.StatusConflict)
+}
+
+// IsPreconditionFailed reports whether err is an APIError caused by a conditional request (see IfMatch)
+// for an object that was modified in the meantime
+func IsPreconditionFailed(err error) bool {
+	return hasStatus(err, http.StatusPreconditionFailed)
+}
+
+// IsUnauthorized reports whether err is an APIError caused by a missing, invalid or expired token
+func IsUnauthorized(err error) bool {
+	return hasStatus(err, http.StatusUnauthorized)
+}
+
+// IsForbidden reports whether err is an APIError caused by the principal lacking permissions
+func IsForbidden(err error) bool {
+	return hasStatus(err, http.StatusForbidden)
+}
+
+// IsRetryable reports whether err is an APIError for a transient failure that may succeed if retried
+func IsRetryable(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.Retryable
+}
+
+func hasStatus(err error, status int) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == status
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func truncate(s string, maxLen int) string {
+	if len(s) <= maxLen {
+		return s
+	}
+	return s[:maxLen] + "..."
+}
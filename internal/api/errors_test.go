@@ -0,0 +1,110 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+//
+// SPDX-License-Identifier: MPL-2.0
+
+//go:build unit
+
+package api_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cisco-open/terraform-provider-observability/internal/api"
+)
+
+const sampleRequestID = "sample_request_id"
+
+func TestAPIErrorFromPayload(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/problem+json")
+		w.Header().Set("X-Request-Id", sampleRequestID)
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"type": "https://example.com/not-found", "title": "Not Found",` +
+			` "detail": "object test not found", "errorCode": "KS-404"}`))
+	}))
+	defer srv.Close()
+
+	ac := &api.AppdClient{
+		URL:       srv.URL,
+		APIClient: srv.Client(),
+		Token:     token,
+	}
+
+	_, err := ac.GetObject(context.Background(), sampleObjectType, sampleObjectID, sampleLayerID, sampleLayerType)
+
+	var apiErr *api.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("GetObject returned %v, expected an *api.APIError", err)
+	}
+	if apiErr.StatusCode != http.StatusNotFound {
+		t.Errorf("Got status %d, expected %d", apiErr.StatusCode, http.StatusNotFound)
+	}
+	if apiErr.Code != "KS-404" {
+		t.Errorf("Got error code %q, expected %q", apiErr.Code, "KS-404")
+	}
+	if apiErr.Message != "object test not found" {
+		t.Errorf("Got message %q, expected %q", apiErr.Message, "object test not found")
+	}
+	if apiErr.RequestID != sampleRequestID {
+		t.Errorf("Got request ID %q, expected %q", apiErr.RequestID, sampleRequestID)
+	}
+	if apiErr.Method != http.MethodGet {
+		t.Errorf("Got method %q, expected %q", apiErr.Method, http.MethodGet)
+	}
+	if !api.IsNotFound(err) || api.IsConflict(err) || api.IsRetryable(err) {
+		t.Errorf("Unexpected classification of error %v", err)
+	}
+}
+
+func TestAPIErrorClassification(t *testing.T) {
+	tests := []struct {
+		status    int
+		body      string
+		check     func(error) bool
+		retryable bool
+		message   string
+	}{
+		{http.StatusConflict, `{"message": "object already exists"}`, api.IsConflict, false, "object already exists"},
+		{http.StatusUnauthorized, `{"error": "invalid_token", "error_description": "token expired"}`, api.IsUnauthorized, false, "token expired"},
+		{http.StatusForbidden, `{"title": "Forbidden"}`, api.IsForbidden, false, "Forbidden"},
+		{http.StatusServiceUnavailable, `upstream connect error`, api.IsRetryable, true, "upstream connect error"},
+		{http.StatusTooManyRequests, ``, api.IsRetryable, true, http.StatusText(http.StatusTooManyRequests)},
+	}
+
+	for _, test := range tests {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(test.status)
+			_, _ = w.Write([]byte(test.body))
+		}))
+
+		ac := &api.AppdClient{
+			URL:       srv.URL,
+			APIClient: srv.Client(),
+			Token:     token,
+		}
+
+		err := ac.DeleteObject(context.Background(), sampleObjectType, sampleObjectID, sampleLayerID, sampleLayerType)
+		srv.Close()
+
+		var apiErr *api.APIError
+		if !errors.As(err, &apiErr) {
+			t.Errorf("Status %d: got %v, expected an *api.APIError", test.status, err)
+			continue
+		}
+		if !test.check(err) {
+			t.Errorf("Status %d: error %v was not classified as expected", test.status, err)
+		}
+		if apiErr.Retryable != test.retryable {
+			t.Errorf("Status %d: got retryable %v, expected %v", test.status, apiErr.Retryable, test.retryable)
+		}
+		if apiErr.Message != test.message {
+			t.Errorf("Status %d: got message %q, expected %q", test.status, apiErr.Message, test.message)
+		}
+	}
+}
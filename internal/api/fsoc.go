@@ -0,0 +1,89 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+//
+// SPDX-License-Identifier: MPL-2.0
+
+package api
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	fsocConfigEnvVar   = "FSOC_CONFIG" // overrides the location of the fsoc config file
+	fsocConfigFileName = ".fsoc"       // fsoc config file in the home directory
+)
+
+// FsocProfile holds the connection settings of an fsoc profile (a "context" in the fsoc config file),
+// including the tokens fsoc cached for it when the user last logged in
+type FsocProfile struct {
+	Name         string `yaml:"name"`
+	AuthMethod   string `yaml:"auth_method"`
+	URL          string `yaml:"url"`
+	Server       string `yaml:"server"` // host name used by older fsoc versions instead of url
+	Tenant       string `yaml:"tenant"`
+	User         string `yaml:"user"`
+	SecretFile   string `yaml:"secret_file"`
+	Token        string `yaml:"token"`
+	RefreshToken string `yaml:"refresh_token"`
+}
+
+// fsocConfig is the fsoc config file, which holds all the profiles
+type fsocConfig struct {
+	CurrentContext string        `yaml:"current_context"`
+	Contexts       []FsocProfile `yaml:"contexts"`
+}
+
+// LoadFsocProfile reads the named profile from the fsoc config file ($FSOC_CONFIG or ~/.fsoc);
+// an empty name selects fsoc's current profile
+func LoadFsocProfile(name string) (*FsocProfile, error) {
+	file, err := fsocConfigFile()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the fsoc config file %q: %w", file, err)
+	}
+
+	var config fsocConfig
+	if err = yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse the fsoc config file %q: %w", file, err)
+	}
+
+	if name == "" {
+		name = config.CurrentContext
+	}
+	for i := range config.Contexts {
+		profile := &config.Contexts[i]
+		if profile.Name != name {
+			continue
+		}
+		if profile.URL == "" && profile.Server != "" {
+			profile.URL = "https://" + profile.Server
+		}
+		profile.URL = strings.TrimSuffix(profile.URL, "/")
+		return profile, nil
+	}
+
+	return nil, fmt.Errorf("profile %q not found in the fsoc config file %q", name, file)
+}
+
+func fsocConfigFile() (string, error) {
+	if file := os.Getenv(fsocConfigEnvVar); file != "" {
+		return file, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate the fsoc config file: %w", err)
+	}
+	return filepath.Join(home, fsocConfigFileName), nil
+}
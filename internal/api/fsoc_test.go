@@ -0,0 +1,72 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+//
+// SPDX-License-Identifier: MPL-2.0
+
+//go:build unit
+
+package api_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cisco-open/terraform-provider-observability/internal/api"
+)
+
+const fsocConfig = `contexts:
+    - name: default
+      auth_method: oauth
+      url: https://mytenant.observe.appdynamics.com/
+      tenant: sample_tenant
+      user: sample_user
+      token: sample_token
+      refresh_token: sample_refresh
+    - name: automation
+      auth_method: service-principal
+      server: other.observe.appdynamics.com
+      tenant: other_tenant
+      secret_file: /home/user/credentials.json
+current_context: automation
+`
+
+func TestLoadFsocProfile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "fsoc.yaml")
+	if err := os.WriteFile(file, []byte(fsocConfig), 0o600); err != nil {
+		t.Fatalf("Failed to write the fsoc config file: %v", err)
+	}
+	t.Setenv("FSOC_CONFIG", file)
+
+	profile, err := api.LoadFsocProfile("default")
+	if err != nil {
+		t.Fatalf("LoadFsocProfile returned an error: %v", err)
+	}
+	expected := api.FsocProfile{
+		Name:         "default",
+		AuthMethod:   "oauth",
+		URL:          "https://mytenant.observe.appdynamics.com",
+		Tenant:       "sample_tenant",
+		User:         "sample_user",
+		Token:        "sample_token",
+		RefreshToken: "sample_refresh",
+	}
+	if *profile != expected {
+		t.Errorf("Got profile %+v, expected %+v", *profile, expected)
+	}
+
+	// the current profile is used when no name is given, older configs only have the server name
+	profile, err = api.LoadFsocProfile("")
+	if err != nil {
+		t.Fatalf("LoadFsocProfile returned an error: %v", err)
+	}
+	if profile.Name != "automation" || profile.URL != "https://other.observe.appdynamics.com" ||
+		profile.SecretFile != "/home/user/credentials.json" {
+		t.Errorf("Got profile %+v, expected the automation profile", *profile)
+	}
+
+	if _, err = api.LoadFsocProfile("missing"); err == nil {
+		t.Errorf("LoadFsocProfile returned no error for a missing profile")
+	}
+}
@@ -0,0 +1,112 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+//
+// SPDX-License-Identifier: MPL-2.0
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/apex/log"
+)
+
+var (
+	// ErrInvalidCredentials is returned by Login when the platform rejects the username or password
+	ErrInvalidCredentials = errors.New("invalid username or password")
+	// ErrMFARequired is returned by Login when the user must complete multi-factor authentication,
+	// which is not possible without a browser; use the oauth authentication method instead
+	ErrMFARequired = errors.New("multi-factor authentication is required for this user")
+)
+
+// OAuth error ids and description keywords signaling that the user has to complete MFA
+var mfaErrorIDs = []string{"mfa_required", "interaction_required", "login_required"}
+var mfaDescKeywords = []string{"mfa", "multi-factor", "multifactor", "two-factor", "2fa"}
+
+// headlessLogin exchanges the username and password for tokens (OAuth resource owner password
+// credentials grant), so no browser is needed
+func (ac *AppdClient) headlessLogin(ctx context.Context) error {
+	log.Infof("Starting headless authentication flow")
+
+	if ac.Username == "" || ac.Password == "" {
+		return errors.New("headless login failed: both username and password are required")
+	}
+
+	// prepare urlencoded data body
+	values := url.Values{}
+	values.Add("client_id", oauth2ClientID)
+	values.Add("grant_type", "password")
+	values.Add("username", ac.Username)
+	values.Add("password", ac.Password)
+
+	// create a POST HTTP request
+	tokenURI := oauthURIWithSuffix(ac, oauth2TokenURISuffix)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURI, strings.NewReader(values.Encode()))
+	if err != nil {
+		return fmt.Errorf("failed to create a login request for %q: %w", tokenURI, err)
+	}
+	req.Header.Add("Accept", jsonContentType)
+	req.Header.Add("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+
+	resp, err := ac.do(req)
+	if err != nil {
+		return fmt.Errorf("failed to request auth (%q): %w", tokenURI, err)
+	}
+
+	// read body (success or error)
+	defer resp.Body.Close()
+	respBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed reading login response from %q: %w", tokenURI, err)
+	}
+	if resp.StatusCode/100 != 2 {
+		return headlessLoginError(resp, respBytes)
+	}
+
+	// parse tokens
+	var token appTokens
+	if err := json.Unmarshal(respBytes, &token); err != nil {
+		return fmt.Errorf("failed to JSON parse the response as a token object: %w", err)
+	}
+	if token.AccessToken == "" {
+		return errors.New("headless login failed: the response carries no access token")
+	}
+	log.Info("Login returned a valid token")
+	ac.setTokens(&token)
+
+	return nil
+}
+
+// headlessLoginError maps a failed password grant to ErrMFARequired or ErrInvalidCredentials when possible,
+// keeping the details reported by the token endpoint
+func headlessLoginError(resp *http.Response, body []byte) error {
+	details := tokenEndpointError(resp, body)
+
+	var errobj oauthErrorPayload
+	_ = json.Unmarshal(body, &errobj) // best effort, details already covers non-JSON bodies
+
+	desc := strings.ToLower(errobj.ErrorDesc)
+	for _, id := range mfaErrorIDs {
+		if errobj.Error == id {
+			return fmt.Errorf("login failed: %w: %w", ErrMFARequired, details)
+		}
+	}
+	for _, keyword := range mfaDescKeywords {
+		if strings.Contains(desc, keyword) {
+			return fmt.Errorf("login failed: %w: %w", ErrMFARequired, details)
+		}
+	}
+
+	if errobj.Error == "invalid_grant" || resp.StatusCode == http.StatusUnauthorized {
+		return fmt.Errorf("login failed: %w: %w", ErrInvalidCredentials, details)
+	}
+	return fmt.Errorf("login failed: %w", details)
+}
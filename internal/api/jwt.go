@@ -0,0 +1,86 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+//
+// SPDX-License-Identifier: MPL-2.0
+
+package api
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// jwtClaims holds the registered claims of an access token the client relies on
+type jwtClaims struct {
+	Subject  string `json:"sub"`
+	Issuer   string `json:"iss"`
+	Expiry   int64  `json:"exp"` // seconds since the epoch, 0 if the token does not expire
+	IssuedAt int64  `json:"iat"`
+
+	// the tenant claim is named differently depending on the kind of principal
+	Tenant        string `json:"tenant"`
+	TenantID      string `json:"tenantId"`
+	SnakeTenantID string `json:"tenant_id"`
+}
+
+// TokenInfo describes who the access token was issued to, as claimed by the token itself
+type TokenInfo struct {
+	Principal string    // ID of the user or principal the client acts as
+	Tenant    string    // tenant the token was issued for, empty if the token does not say
+	ExpiresAt time.Time // zero if the token does not expire
+}
+
+// TokenInfo decodes the claims of the current access token. It fails if there is no token yet (see Login)
+// or if it is not a JWT, since platform tokens are not guaranteed to be one.
+func (ac *AppdClient) TokenInfo() (*TokenInfo, error) {
+	ac.tokenMu.Lock()
+	token := ac.Token
+	ac.tokenMu.Unlock()
+
+	if token == "" {
+		return nil, errors.New("no access token, log in first")
+	}
+	claims, err := parseJWTClaims(token)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TokenInfo{
+		Principal: claims.Subject,
+		Tenant:    firstNonEmpty(claims.Tenant, claims.TenantID, claims.SnakeTenantID),
+		ExpiresAt: claims.expiresAt(),
+	}, nil
+}
+
+// parseJWTClaims decodes the claims of a JWT access token. The signature is not verified, the claims are
+// only used to anticipate what the platform will accept.
+func parseJWTClaims(token string) (*jwtClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 { //nolint:gomnd // header, payload and signature
+		return nil, errors.New("the access token is not a JWT")
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode the access token claims: %w", err)
+	}
+
+	var claims jwtClaims
+	if err = json.Unmarshal(payload, &claims); err != nil {
+		return nil, fmt.Errorf("failed to parse the access token claims: %w", err)
+	}
+	return &claims, nil
+}
+
+// expiresAt returns when the token expires, zero if it does not
+func (c *jwtClaims) expiresAt() time.Time {
+	if c.Expiry == 0 {
+		return time.Time{}
+	}
+	return time.Unix(c.Expiry, 0)
+}
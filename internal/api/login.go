@@ -7,38 +7,90 @@
 package api
 
 import (
+	"context"
 	"fmt"
 	"net/http"
+	"sync"
+	"time"
+
+	"github.com/apex/log"
 )
 
 type AppdClient struct {
-	Username     string
-	Password     string
-	Tenant       string
-	AuthMethod   string
-	URL          string
-	Token        string
-	RefreshToken string
-	SecretFile   string
-	APIClient    *http.Client
+	Username          string
+	Password          string
+	Tenant            string
+	AuthMethod        string
+	URL               string
+	Token             string
+	RefreshToken      string
+	SecretFile        string
+	ClientID          string // service principal client ID, used instead of SecretFile when set
+	ClientSecret      string // service principal secret, used along with ClientID
+	CredentialProcess string // command printing an access token as JSON, for the credential-process method
+	APIClient         *http.Client
+	MaxRetries        int                        // number of times a failed knowledge store request is retried, 0 disables retries
+	RetryMaxWait      time.Duration              // upper bound for the delay between retries, defaults to DefaultRetryMaxWait
+	TokenCacheDir     string                     // directory of the on-disk token cache (see DefaultTokenCacheDir), empty disables it
+	DevicePrompt      func(*DeviceAuthorization) // tells the user how to approve a device code login, logged if nil
+	CallbackAddr      string                     // host:port of the OAuth callback server, DefaultOAuthCallbackAddr if empty, port 0 for any
+	LoginTimeout      time.Duration              // how long to wait for the OAuth login in the browser, DefaultLoginTimeout if zero
+	OpenBrowser       func(uri string) error     // opens the OAuth login page, the system browser if nil
+
+	MaxConcurrentRequests int           // upper bound for the requests in flight to the platform, 0 for no limit
+	RequestTimeout        time.Duration // deadline of each request (not of its retries), 0 for none
+
+	tokenMu     sync.Mutex // guards Token, RefreshToken and tokenExpiry against concurrent refreshes
+	tokenExpiry time.Time  // when Token expires, zero if unknown
+	cachedToken bool       // Token was handed over by UseCachedTokens and not yet used by Login
+
+	requestSlots     chan struct{} // semaphore of MaxConcurrentRequests, see do
+	requestSlotsOnce sync.Once
 }
 
-func (ac *AppdClient) Login() error {
+// Login authenticates using the configured AuthMethod and stores the obtained tokens in the client.
+// The access token is subsequently refreshed automatically whenever it is about to expire.
+// With a TokenCacheDir, a still valid access token cached by a previous run is reused and a cached
+// refresh token is tried before falling back to the authentication flow.
+func (ac *AppdClient) Login(ctx context.Context) error {
+	ac.tokenMu.Lock()
+	defer ac.tokenMu.Unlock()
+
+	if ac.cachedToken {
+		ac.cachedToken = false
+		if ac.tokenExpiry.IsZero() || time.Now().Add(tokenExpirySkew).Before(ac.tokenExpiry) {
+			log.Infof("Reusing the cached access token")
+			return nil
+		}
+		log.Infof("The cached access token expired at %v, logging in again", ac.tokenExpiry.Format(time.RFC3339))
+	}
+	if ac.loadCachedTokens() {
+		log.Infof("Reusing the access token from the token cache")
+		return nil
+	}
+	return ac.login(ctx)
+}
+
+// login runs the authentication flow for the configured AuthMethod; the caller must hold tokenMu
+func (ac *AppdClient) login(ctx context.Context) error {
 	var authErr error
 	switch ac.AuthMethod {
 	case authMethodOAuth:
-		authErr = ac.oauthLogin()
+		authErr = ac.oauthLogin(ctx)
 	case headless:
-		// TODO: implement the headless authentication using username and password
+		authErr = ac.headlessLogin(ctx)
 	case servicePrincipal:
-		authErr = ac.servicePrincipalLogin()
+		authErr = ac.servicePrincipalLogin(ctx)
+	case agentPrincipal:
+		authErr = ac.agentPrincipalLogin(ctx)
+	case authMethodAccessToken:
+		authErr = ac.accessTokenLogin()
+	case authMethodDeviceCode:
+		authErr = ac.deviceCodeLogin(ctx)
+	case credentialProcess:
+		authErr = ac.credentialProcessLogin(ctx)
 	default:
 		panic(fmt.Sprintf("bug: unhandled authentication method %q", ac.AuthMethod))
 	}
-	if authErr != nil {
-		return authErr
-	}
-
-	// PROBLEM: we should return the login credentials to terraform for storing purposes ?
-	return nil
+	return authErr
 }
@@ -9,12 +9,17 @@
 package api_test
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/apex/log"
 	"github.com/cisco-open/terraform-provider-observability/internal/api"
@@ -23,6 +28,9 @@ import (
 const (
 	servicePrincipal = "service-principal"
 	oauth            = "oauth"
+	headless         = "headless"
+	username         = "sample_user"
+	password         = "sample_password"
 	secretsFileName  = "sample_secrets_"
 	payload          = `{"Client ID": "sample_client_id", "Secret": "sample_secret"}`
 	tenant           = "sample_tenant"
@@ -54,7 +62,7 @@ func TestServicePrincipalLogin(t *testing.T) {
 	}
 
 	// Call the Login method
-	err = ac.Login()
+	err = ac.Login(context.Background())
 
 	// Check for any errors
 	if err != nil {
@@ -67,20 +75,239 @@ func TestServicePrincipalLogin(t *testing.T) {
 	}
 }
 
-//lint:ignore U1000 Ignore unused function temporarily for debugging
-func _TestOauthLogin(t *testing.T) {
+// newClientCredentialsServer mocks the token endpoint, accepting only the sample client ID and secret
+func newClientCredentialsServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, secret, ok := r.BasicAuth()
+		if !ok || id != "sample_client_id" || secret != "sample_secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"access_token": "%s"}`, token)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestServicePrincipalCredentialFileLayouts(t *testing.T) {
+	srv := newClientCredentialsServer(t)
+
+	tests := []struct {
+		name     string
+		contents string
+	}{
+		{"json", payload},
+		{"snake case json", `{"client_id": "sample_client_id", "client_secret": "sample_secret"}`},
+		{"camel case json", `{"clientId": "sample_client_id", "clientSecret": "sample_secret"}`},
+		{"lower case json", `{"client id": "sample_client_id", "secret": "sample_secret"}`},
+		{"yaml", "client_id: sample_client_id\nclient_secret: sample_secret\n"},
+	}
+
+	for _, test := range tests {
+		tmpfile, err := createTempJSONFile(test.contents)
+		if err != nil {
+			t.Fatalf("Failed during creation of temporary credentials file: %v", err)
+		}
+		t.Cleanup(func() { os.Remove(tmpfile) })
+
+		ac := &api.AppdClient{
+			URL:        srv.URL,
+			Tenant:     tenant,
+			AuthMethod: servicePrincipal,
+			SecretFile: tmpfile,
+			APIClient:  srv.Client(),
+		}
+		if err = ac.Login(context.Background()); err != nil {
+			t.Errorf("%s: Login returned an error: %v", test.name, err)
+		}
+	}
+}
+
+func TestServicePrincipalCredentialFileJSONEscapes(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if id, secret, ok := r.BasicAuth(); !ok || id != "sample_client_id" || secret != "sample/secret" {
+			t.Errorf("Got client ID %q and secret %q", id, secret)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"access_token": "%s"}`, token)
+	}))
+	defer srv.Close()
+
+	// escaped slashes are valid JSON, but not valid YAML
+	tmpfile, err := createTempJSONFile(`{"Client ID": "sample_client_id", "Secret": "sample\/secret"}`)
+	if err != nil {
+		t.Fatalf("Failed during creation of temporary credentials file: %v", err)
+	}
+	defer os.Remove(tmpfile)
+
+	ac := &api.AppdClient{
+		URL:        srv.URL,
+		Tenant:     tenant,
+		AuthMethod: servicePrincipal,
+		SecretFile: tmpfile,
+		APIClient:  srv.Client(),
+	}
+	if err = ac.Login(context.Background()); err != nil {
+		t.Errorf("Login returned an error: %v", err)
+	}
+}
+
+func TestServicePrincipalCredentialFileIncomplete(t *testing.T) {
+	tmpfile, err := createTempJSONFile(`{"client_id": "sample_client_id"}`)
+	if err != nil {
+		t.Fatalf("Failed during creation of temporary credentials file: %v", err)
+	}
+	defer os.Remove(tmpfile)
+
+	ac := &api.AppdClient{
+		URL:        "http://127.0.0.1:0",
+		Tenant:     tenant,
+		AuthMethod: servicePrincipal,
+		SecretFile: tmpfile,
+		APIClient:  http.DefaultClient,
+	}
+	if err = ac.Login(context.Background()); err == nil || !strings.Contains(err.Error(), "must contain") {
+		t.Errorf("Login returned %v, expected an incomplete credentials error", err)
+	}
+}
+
+func TestServicePrincipalInlineCredentials(t *testing.T) {
+	srv := newClientCredentialsServer(t)
+
+	ac := &api.AppdClient{
+		URL:          srv.URL,
+		Tenant:       tenant,
+		AuthMethod:   servicePrincipal,
+		SecretFile:   "/does/not/exist.json", // ignored in favor of the inline credentials
+		ClientID:     "sample_client_id",
+		ClientSecret: "sample_secret",
+		APIClient:    srv.Client(),
+	}
+	if err := ac.Login(context.Background()); err != nil {
+		t.Fatalf("Login returned an error: %v", err)
+	}
+	if ac.Token != token {
+		t.Errorf("Login failed to set the access token")
+	}
+
+	ac.ClientSecret = ""
+	if err := ac.Login(context.Background()); err == nil {
+		t.Errorf("Login returned no error without a client secret")
+	}
+}
+
+func TestHeadlessLogin(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/auth/"+tenant+"/default/oauth2/token" {
+			t.Errorf("Unexpected request to %s", r.URL.Path)
+		}
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("Failed to parse the login request: %v", err)
+		}
+		if r.PostForm.Get("grant_type") != "password" || r.PostForm.Get("username") != username ||
+			r.PostForm.Get("password") != password {
+			t.Errorf("Unexpected login request %v", r.PostForm)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"access_token": "%s", "refresh_token": "sample_refresh", "expires_in": 3600}`, token)
+	}))
+	defer srv.Close()
+
+	ac := &api.AppdClient{
+		URL:        srv.URL,
+		Tenant:     tenant,
+		AuthMethod: headless,
+		Username:   username,
+		Password:   password,
+		APIClient:  srv.Client(),
+	}
+
+	if err := ac.Login(context.Background()); err != nil {
+		t.Fatalf("Login returned an error: %v", err)
+	}
+	if ac.Token != token || ac.RefreshToken != "sample_refresh" {
+		t.Errorf("Login failed to set the tokens, got %q and %q", ac.Token, ac.RefreshToken)
+	}
+}
+
+func TestHeadlessLoginErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		body     string
+		expected error
+	}{
+		{"bad password", http.StatusBadRequest, `{"error": "invalid_grant", "error_description": "bad credentials"}`,
+			api.ErrInvalidCredentials},
+		{"unauthorized", http.StatusUnauthorized, `Unauthorized`, api.ErrInvalidCredentials},
+		{"mfa error id", http.StatusBadRequest, `{"error": "mfa_required"}`, api.ErrMFARequired},
+		{"mfa description", http.StatusForbidden, `{"error": "access_denied", "error_description": "MFA enrollment needed"}`,
+			api.ErrMFARequired},
+		{"server error", http.StatusInternalServerError, `{"error": "server_error"}`, nil},
+	}
+
+	for _, test := range tests {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(test.status)
+			_, _ = w.Write([]byte(test.body))
+		}))
+
+		ac := &api.AppdClient{
+			URL:        srv.URL,
+			Tenant:     tenant,
+			AuthMethod: headless,
+			Username:   username,
+			Password:   password,
+			APIClient:  srv.Client(),
+		}
+
+		err := ac.Login(context.Background())
+		srv.Close()
+
+		if err == nil {
+			t.Errorf("%s: Login returned no error", test.name)
+			continue
+		}
+		if test.expected != nil && !errors.Is(err, test.expected) {
+			t.Errorf("%s: Login returned %v, expected %v", test.name, err, test.expected)
+		}
+		if test.expected == nil && (errors.Is(err, api.ErrInvalidCredentials) || errors.Is(err, api.ErrMFARequired)) {
+			t.Errorf("%s: Login returned %v, expected a generic error", test.name, err)
+		}
+	}
+}
+
+func TestHeadlessLoginMissingCredentials(t *testing.T) {
+	ac := &api.AppdClient{
+		URL:        "http://127.0.0.1:0",
+		Tenant:     tenant,
+		AuthMethod: headless,
+		Username:   username,
+		APIClient:  http.DefaultClient,
+	}
+
+	if err := ac.Login(context.Background()); err == nil {
+		t.Errorf("Login returned no error without a password")
+	}
+}
+
+// newOAuthServer mocks the authorize endpoint, redirecting the browser to the callback with the given
+// query (plus the state), and the token endpoint
+func newOAuthServer(t *testing.T, callbackQuery string) *httptest.Server {
+	t.Helper()
+
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		switch r.URL.Path {
 		case "/auth/" + tenant + "/default/oauth2/authorize":
 			// Simulate authorization code grant flow
 			// Redirect user to callback URL with mock authorization code
-			redirectURL := r.FormValue("redirect_uri") + "?code=mockAuthorizationCode&scope=A&state=" + r.FormValue("state")
+			redirectURL := r.FormValue("redirect_uri") + "?" + callbackQuery + "&state=" + r.FormValue("state")
 			http.Redirect(w, r, redirectURL, http.StatusFound)
-		case "/callback":
-			// Simulate callback handler
-			// Respond with success message
-			w.WriteHeader(http.StatusOK)
-			_, _ = w.Write([]byte("Login successful. You can close this browser window."))
 		case "/auth/" + tenant + "/default/oauth2/token":
 			// Simulate token exchange request
 			// Return mock tokens
@@ -95,18 +322,40 @@ func _TestOauthLogin(t *testing.T) {
 			_, _ = w.Write([]byte("Not found"))
 		}
 	}))
-	defer srv.Close()
+	t.Cleanup(srv.Close)
+	return srv
+}
 
-	// Create a new AppdClient with mock URL and credentials
-	ac := &api.AppdClient{
-		URL:        srv.URL,
-		Tenant:     tenant,
-		AuthMethod: oauth,
-		APIClient:  srv.Client(),
+// newOAuthClient returns a client whose "browser" follows the login page redirects
+func newOAuthClient(srv *httptest.Server) *api.AppdClient {
+	return &api.AppdClient{
+		URL:          srv.URL,
+		Tenant:       tenant,
+		AuthMethod:   oauth,
+		APIClient:    srv.Client(),
+		CallbackAddr: "127.0.0.1:0",
+		OpenBrowser: func(uri string) error {
+			req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, uri, http.NoBody)
+			if err != nil {
+				return err
+			}
+			go func() {
+				resp, err := http.DefaultClient.Do(req)
+				if err == nil {
+					resp.Body.Close()
+				}
+			}()
+			return nil
+		},
 	}
+}
+
+func TestOauthLogin(t *testing.T) {
+	srv := newOAuthServer(t, "code=mockAuthorizationCode&scope=A")
+	ac := newOAuthClient(srv)
 
 	// Call the Login method
-	err := ac.Login()
+	err := ac.Login(context.Background())
 
 	// Check for any errors
 	if err != nil {
@@ -119,6 +368,54 @@ func _TestOauthLogin(t *testing.T) {
 	}
 }
 
+func TestOauthLoginCallbackError(t *testing.T) {
+	srv := newOAuthServer(t, "error=access_denied&error_description=The+user+declined")
+	ac := newOAuthClient(srv)
+
+	err := ac.Login(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "access_denied") || !strings.Contains(err.Error(), "The user declined") {
+		t.Errorf("Login returned %v, expected the authorization error", err)
+	}
+}
+
+func TestOauthLoginTimeout(t *testing.T) {
+	ac := &api.AppdClient{
+		URL:          "http://127.0.0.1:0",
+		Tenant:       tenant,
+		AuthMethod:   oauth,
+		APIClient:    http.DefaultClient,
+		CallbackAddr: "127.0.0.1:0",
+		LoginTimeout: 50 * time.Millisecond,
+		OpenBrowser:  func(string) error { return nil }, // the user never logs in
+	}
+
+	err := ac.Login(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "not completed within") {
+		t.Errorf("Login returned %v, expected a timeout", err)
+	}
+}
+
+func TestOauthLoginCallbackPortTaken(t *testing.T) {
+	// a port already in use is reported instead of silently switching to the device flow
+	srv := newOAuthServer(t, "code=mockAuthorizationCode&scope=A")
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+
+	ac := newOAuthClient(srv)
+	ac.CallbackAddr = listener.Addr().String()
+	ac.DevicePrompt = func(*api.DeviceAuthorization) {
+		t.Errorf("Login fell back to the device authorization flow")
+	}
+
+	err = ac.Login(context.Background())
+	if err == nil || !strings.Contains(err.Error(), listener.Addr().String()) {
+		t.Errorf("Login returned %v, expected a failure to listen on %v", err, listener.Addr())
+	}
+}
+
 // Helper function to create a temporary JSON file
 func createTempJSONFile(contents string) (string, error) {
 	tmpfile, err := os.CreateTemp("", secretsFileName)
@@ -134,3 +431,54 @@ func createTempJSONFile(contents string) (string, error) {
 
 	return tmpfile.Name(), nil
 }
+
+func TestAgentPrincipalLogin(t *testing.T) {
+	const agentTokenPath = "/auth/agents/token"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		clientID, secret, ok := r.BasicAuth()
+		if r.URL.Path != agentTokenPath || !ok || clientID != "agent_client_id" || secret != "agent_secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"access_token": "%s", "expires_in": 3600}`, token)
+	}))
+	defer srv.Close()
+
+	tmpfile, err := createTempJSONFile(fmt.Sprintf(`clientId: agent_client_id
+clientSecret: agent_secret
+tokenUrl: %s%s
+tenantId: %s
+`, srv.URL, agentTokenPath, tenant))
+	if err != nil {
+		t.Fatalf("Failed during creation of temporary credentials file: %v", err)
+	}
+	defer os.Remove(tmpfile)
+
+	ac := &api.AppdClient{
+		URL:        srv.URL,
+		Tenant:     tenant,
+		AuthMethod: "agent-principal",
+		SecretFile: tmpfile,
+		APIClient:  srv.Client(),
+	}
+
+	if err := ac.Login(context.Background()); err != nil {
+		t.Fatalf("Login returned an error: %v", err)
+	}
+	if ac.Token != token {
+		t.Errorf("Login failed to set the access token")
+	}
+
+	// tenant IDs are not case sensitive
+	ac.Tenant = strings.ToUpper(tenant)
+	if err := ac.Login(context.Background()); err != nil {
+		t.Errorf("Login rejected agent credentials issued for %s: %v", tenant, err)
+	}
+
+	// credentials issued for another tenant are rejected before contacting the platform
+	ac.Tenant = "another_tenant"
+	if err := ac.Login(context.Background()); err == nil {
+		t.Errorf("Login accepted agent credentials issued for another tenant")
+	}
+}
@@ -0,0 +1,116 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+//
+// SPDX-License-Identifier: MPL-2.0
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+)
+
+const mergePatchContentType = "application/merge-patch+json"
+
+// PatchObject is a method used to PATCH the knowledge store object
+// based on the fullyQualifiedTypeName and objectID with a JSON merge patch (RFC 7396) set in the body,
+// leaving the fields not mentioned in the patch untouched
+// layerID which will be the tenant and layerType (TENANT/SOLUTION/...)
+// Pass IfMatch(etag) to only patch the object if it was not modified since it was read
+// It returns the new version (ETag) of the object, empty if the knowledge store did not report it
+// Failed requests are reported as *APIError, use IsPreconditionFailed to detect a concurrent modification
+func (ac *AppdClient) PatchObject(ctx context.Context, fullyQualifiedTypeName, objectID, layerID, layerType string, patch []byte,
+	opts ...RequestOption) (string, error) {
+	url := ac.URL + objectAPIPath + fullyQualifiedTypeName + "/" + objectID
+
+	req, err := ac.newAPIRequest(ctx, http.MethodPatch, url, patch)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", mergePatchContentType)
+	setLayerHeaders(req, layerID, layerType)
+	applyRequestOptions(req, opts)
+
+	// Do request
+	resp, _, err := ac.doRequest(req)
+	if err != nil {
+		return "", err
+	}
+	return resp.Header.Get("ETag"), nil
+}
+
+// CreateMergePatch computes the JSON merge patch (RFC 7396) that turns the original JSON object into
+// the modified one: changed fields are set, removed fields are set to null and unchanged fields are
+// omitted. An empty object ("{}") means there is nothing to change.
+func CreateMergePatch(original, modified []byte) ([]byte, error) {
+	var originalObj, modifiedObj map[string]any
+	if err := json.Unmarshal(original, &originalObj); err != nil {
+		return nil, fmt.Errorf("failed to parse the original JSON object: %w", err)
+	}
+	if err := json.Unmarshal(modified, &modifiedObj); err != nil {
+		return nil, fmt.Errorf("failed to parse the modified JSON object: %w", err)
+	}
+
+	return json.Marshal(mergePatchDiff(originalObj, modifiedObj, nil, false))
+}
+
+// CreateManagedMergePatch is like CreateMergePatch, but only removes the fields of the original object which
+// are also in managed, the object as last applied by the caller. Fields the caller never managed, e.g. the
+// ones of an imported object, are left untouched; a nil managed object removes nothing.
+func CreateManagedMergePatch(original, modified, managed []byte) ([]byte, error) {
+	var originalObj, modifiedObj, managedObj map[string]any
+	if err := json.Unmarshal(original, &originalObj); err != nil {
+		return nil, fmt.Errorf("failed to parse the original JSON object: %w", err)
+	}
+	if err := json.Unmarshal(modified, &modifiedObj); err != nil {
+		return nil, fmt.Errorf("failed to parse the modified JSON object: %w", err)
+	}
+	if managed != nil {
+		if err := json.Unmarshal(managed, &managedObj); err != nil {
+			return nil, fmt.Errorf("failed to parse the managed JSON object: %w", err)
+		}
+	}
+
+	return json.Marshal(mergePatchDiff(originalObj, modifiedObj, managedObj, true))
+}
+
+// mergePatchDiff computes the patch from original to modified; when restricted, only the fields also in
+// managed are removed
+func mergePatchDiff(original, modified, managed map[string]any, restricted bool) map[string]any {
+	patch := make(map[string]any)
+
+	for k := range original {
+		if _, ok := modified[k]; ok {
+			continue
+		}
+		if _, ok := managed[k]; restricted && !ok {
+			continue // never managed, left as is
+		}
+		patch[k] = nil // removed
+	}
+
+	for k, modifiedValue := range modified {
+		originalValue, ok := original[k]
+		if ok && reflect.DeepEqual(originalValue, modifiedValue) {
+			continue // unchanged
+		}
+
+		// nested objects are patched recursively, anything else (including arrays) is replaced
+		originalMap, originalIsMap := originalValue.(map[string]any)
+		modifiedMap, modifiedIsMap := modifiedValue.(map[string]any)
+		if ok && originalIsMap && modifiedIsMap {
+			managedMap, _ := managed[k].(map[string]any)
+			if nested := mergePatchDiff(originalMap, modifiedMap, managedMap, restricted); len(nested) > 0 {
+				patch[k] = nested
+			}
+			continue
+		}
+		patch[k] = modifiedValue
+	}
+
+	return patch
+}
@@ -0,0 +1,120 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+//
+// SPDX-License-Identifier: MPL-2.0
+
+//go:build unit
+
+package api_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cisco-open/terraform-provider-observability/internal/api"
+)
+
+func TestCreateMergePatch(t *testing.T) {
+	tests := []struct {
+		original string
+		modified string
+		expected string
+	}{
+		{`{"a": 1, "b": "x"}`, `{"a": 1, "b": "x"}`, `{}`},                                     // unchanged
+		{`{"a": 1, "b": "x"}`, `{"a": 2, "b": "x"}`, `{"a":2}`},                                // changed field
+		{`{"a": 1, "b": "x"}`, `{"a": 1}`, `{"b":null}`},                                       // removed field
+		{`{"a": 1}`, `{"a": 1, "c": true}`, `{"c":true}`},                                      // added field
+		{`{"n": {"x": 1, "y": 2}}`, `{"n": {"x": 1, "y": 3}}`, `{"n":{"y":3}}`},                // nested object
+		{`{"n": {"x": 1}}`, `{"n": "flat"}`, `{"n":"flat"}`},                                   // object replaced by value
+		{`{"l": [1, 2]}`, `{"l": [1, 2, 3]}`, `{"l":[1,2,3]}`},                                 // arrays are replaced
+		{`{"a": 1, "n": {"x": 1}}`, `{"n": {"x": 1, "z": null}}`, `{"a":null,"n":{"z":null}}`}, // mixed
+	}
+
+	for _, test := range tests {
+		patch, err := api.CreateMergePatch([]byte(test.original), []byte(test.modified))
+		if err != nil {
+			t.Errorf("CreateMergePatch(%s, %s) returned an unexpected error: %v", test.original, test.modified, err)
+			continue
+		}
+		if string(patch) != test.expected {
+			t.Errorf("CreateMergePatch(%s, %s) = %s, expected %s", test.original, test.modified, patch, test.expected)
+		}
+	}
+
+	if _, err := api.CreateMergePatch([]byte(`[1]`), []byte(`{}`)); err == nil {
+		t.Errorf("CreateMergePatch accepted a non-object document")
+	}
+}
+
+func TestCreateManagedMergePatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		original string
+		modified string
+		managed  string
+		expected string
+	}{
+		{"ManagedFieldRemoved", `{"a": 1, "b": "x"}`, `{"a": 1}`, `{"a": 1, "b": "x"}`, `{"b":null}`},
+		{"UnmanagedFieldKept", `{"a": 1, "b": "x", "c": 2}`, `{"a": 1}`, `{"a": 1, "b": "x"}`, `{"b":null}`},
+		{"ImportedFieldsKept", `{"a": 1, "b": "x", "n": {"x": 1}}`, `{"a": 2}`, "", `{"a":2}`},
+		{"NestedUnmanagedFieldKept", `{"n": {"x": 1, "y": 2}}`, `{"n": {"x": 3}}`, `{"n": {"x": 1}}`, `{"n":{"x":3}}`},
+		{"NestedManagedFieldRemoved", `{"n": {"x": 1, "y": 2}}`, `{"n": {"x": 1}}`, `{"n": {"x": 1, "y": 2}}`, `{"n":{"y":null}}`},
+		{"ExplicitNullRemoves", `{"a": 1, "b": "x"}`, `{"a": 1, "b": null}`, "", `{"b":null}`},
+	}
+
+	for _, test := range tests {
+		var managed []byte
+		if test.managed != "" {
+			managed = []byte(test.managed)
+		}
+		patch, err := api.CreateManagedMergePatch([]byte(test.original), []byte(test.modified), managed)
+		if err != nil {
+			t.Errorf("%s: CreateManagedMergePatch returned an unexpected error: %v", test.name, err)
+			continue
+		}
+		if string(patch) != test.expected {
+			t.Errorf("%s: got patch %s, expected %s", test.name, patch, test.expected)
+		}
+	}
+}
+
+func TestPatchObject(t *testing.T) {
+	var contentType, method, body string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		method = r.Method
+		payload, _ := io.ReadAll(r.Body)
+		body = string(payload)
+		w.Header().Set("ETag", `"v2"`)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	ac := &api.AppdClient{
+		URL:       srv.URL,
+		APIClient: srv.Client(),
+		Token:     token,
+	}
+
+	patch := []byte(`{"region":"us-west-2"}`)
+	etag, err := ac.PatchObject(context.Background(), sampleObjectType, sampleObjectID, sampleLayerID, sampleLayerType, patch)
+	if err != nil {
+		t.Fatalf("PatchObject returned an unexpected error: %v", err)
+	}
+	if etag != `"v2"` {
+		t.Errorf("Got ETag %s, expected the version reported by the patch", etag)
+	}
+	if method != http.MethodPatch {
+		t.Errorf("Got method %s, expected %s", method, http.MethodPatch)
+	}
+	if contentType != "application/merge-patch+json" {
+		t.Errorf("Got content type %s, expected application/merge-patch+json", contentType)
+	}
+	if body != `{"region":"us-west-2"}` {
+		t.Errorf("Got body %s, expected the patch", body)
+	}
+}
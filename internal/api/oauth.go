@@ -8,9 +8,13 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"html/template"
 	"io"
+	"net"
 	"net/http"
 	"net/url"
 	"os"
@@ -47,18 +51,19 @@ type oauthErrorPayload struct {
 	StatusCode int    `json:"status_code"`
 }
 
-func (ac *AppdClient) oauthLogin() error {
+func (ac *AppdClient) oauthLogin(ctx context.Context) error {
 	log.Infof("Starting OAuth authentication flow")
 
 	// try refresh token if present
 	if ac.RefreshToken != "" {
-		// refresh and return if successful
-		err := oauthRefreshToken(ac)
+		// refresh and return if successful, fall back to the browser flow otherwise
+		err := oauthRefreshToken(ctx, ac)
 		if err == nil {
 			log.Infof("Access token refreshed successfully")
 			return nil
 		}
-		return err
+		log.Warnf("Failed to refresh the access token, logging in again: %v", err)
+		ac.RefreshToken = ""
 	}
 
 	// generate code verifier
@@ -73,10 +78,19 @@ func (ac *AppdClient) oauthLogin() error {
 		return err
 	}
 
+	// start http server to receive the auth callback, its address is part of the authorization request
+	callback, err := ac.startCallbackServer()
+	if err != nil {
+		return fmt.Errorf("could not start a local http server for auth: %w", err)
+	}
+	defer func() {
+		_ = stopCallbackServer(callback.server) // no check needed, error should be logged
+	}()
+
 	// prepare OAuth2 config
 	conf := &oauth2.Config{
 		ClientID:    oauth2ClientID,
-		RedirectURL: oauthRedirectURI,
+		RedirectURL: callback.redirectURI,
 		Endpoint: oauth2.Endpoint{
 			AuthURL:   oauthURIWithSuffix(ac, oauth2AuthURISuffix),
 			TokenURL:  oauthURIWithSuffix(ac, oauth2TokenURISuffix),
@@ -90,7 +104,12 @@ func (ac *AppdClient) oauthLogin() error {
 	)
 
 	// open browser to perform login, collect auth with a localhost http server
-	authCode, err := getAuthorizationCodes(authCodeURL)
+	authCode, err := ac.getAuthorizationCodes(ctx, callback, authCodeURL)
+	if errors.Is(err, errNoBrowser) {
+		// e.g. over SSH or in a container, the login can still be approved from another machine
+		log.Warnf("%v, falling back to the device authorization flow", err)
+		return ac.deviceCodeLogin(ctx)
+	}
 	if err != nil {
 		return fmt.Errorf("login failed to obtain the authorization code: %w", err)
 	}
@@ -102,20 +121,17 @@ func (ac *AppdClient) oauthLogin() error {
 	}
 
 	// exchange auth code for token
-	// TODO return token
-	token, err := exchangeCodeForToken(conf, ac.APIClient, code, authCode)
+	token, err := exchangeCodeForToken(ctx, ac, conf, code, authCode)
 	if err != nil {
 		return fmt.Errorf("failed to exchange auth code for a token: %v", err.Error())
 	}
 
-	ac.Token = token.AccessToken
-	fmt.Printf("Token is: %s", ac.Token)
-
-	// PROBLEM: where will we store the token....
+	ac.setTokens(token)
 	return nil
 }
 
-func exchangeCodeForToken(conf *oauth2.Config, client *http.Client, codeVerifier string, authCode *authCodes) (*appTokens, error) {
+func exchangeCodeForToken(ctx context.Context, ac *AppdClient, conf *oauth2.Config, codeVerifier string,
+	authCode *authCodes) (*appTokens, error) {
 	log.Infof("Exchanging authorization codes for access token")
 
 	// prepare urlencoded data body
@@ -124,21 +140,23 @@ func exchangeCodeForToken(conf *oauth2.Config, client *http.Client, codeVerifier
 	values.Add("client_id", "default")
 	values.Add("code_verifier", codeVerifier)
 	values.Add("code", authCode.Code)
-	values.Add("redirect_uri", oauthRedirectURI)
+	values.Add("redirect_uri", conf.RedirectURL)
 	bodyReader := bytes.NewReader([]byte(values.Encode()))
 
 	// create a POST HTTP request
-	req, err := http.NewRequest("POST", conf.Endpoint.TokenURL, bodyReader) //nolint:noctx // To be removed in the future
+	req, err := http.NewRequestWithContext(ctx, "POST", conf.Endpoint.TokenURL, bodyReader)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create a request %q: %v", conf.Endpoint.TokenURL, err.Error())
 	}
 	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
 
 	// execute request
-	resp, err := client.Do(req)
+	resp, err := ac.do(req)
 	if err != nil {
 		return nil, fmt.Errorf("POST request to %q failed: %v", req.RequestURI, err.Error())
 	}
+	// closing the body also frees the request slot
+	defer resp.Body.Close()
 
 	if resp.StatusCode/100 != 2 {
 		return nil, fmt.Errorf("request failed, status %q; more info to follow", resp.Status)
@@ -146,7 +164,6 @@ func exchangeCodeForToken(conf *oauth2.Config, client *http.Client, codeVerifier
 
 	// collect response body (whether success or error)
 	var respBytes []byte
-	defer resp.Body.Close()
 	respBytes, err = io.ReadAll(resp.Body)
 	if err != nil {
 		return nil, fmt.Errorf("failed reading response to POST to %q: %v", req.RequestURI, err.Error())
@@ -174,37 +191,29 @@ func exchangeCodeForToken(conf *oauth2.Config, client *http.Client, codeVerifier
 	return &tokenObject, nil
 }
 
-func oauthRefreshToken(cfg *AppdClient) error {
+func oauthRefreshToken(ctx context.Context, cfg *AppdClient) error {
 	log.Infof("Trying to get a new access token using the refresh token")
 
 	// prepare urlencoded data body
 	values := url.Values{}
 	values.Add("client_id", oauth2ClientID)
-	values.Add("redirect_uri", oauthRedirectURI)
 	values.Add("grant_type", "refresh_token")
 	values.Add("refresh_token", cfg.RefreshToken)
 	bodyReader := bytes.NewReader([]byte(values.Encode()))
 
 	// create a POST HTTP request
 	tokenURI := oauthURIWithSuffix(cfg, oauth2TokenURISuffix)
-	req, err := http.NewRequest("POST", tokenURI, bodyReader) //nolint:noctx // To be removed in the future
+	req, err := http.NewRequestWithContext(ctx, "POST", tokenURI, bodyReader)
 	if err != nil {
 		return fmt.Errorf("failed to create a token refresh request %q: %w", tokenURI, err)
 	}
 	req.Header.Add("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
 
-	resp, err := cfg.APIClient.Do(req)
+	resp, err := cfg.do(req)
 	if err != nil {
 		return fmt.Errorf("POST request to %q failed: %v", req.RequestURI, err.Error())
 	}
 
-	// log error if it occurred
-	if resp.StatusCode/100 != 2 {
-		// log error before trying to parse body, more processing later
-		log.Errorf("Request failed, status %q; more info to follow", resp.Status)
-		// fall through
-	}
-
 	var respBytes []byte
 	defer resp.Body.Close()
 	respBytes, err = io.ReadAll(resp.Body)
@@ -212,17 +221,35 @@ func oauthRefreshToken(cfg *AppdClient) error {
 		return fmt.Errorf("failed reading response to POST to %q: %v", req.RequestURI, err.Error())
 	}
 
+	// the refresh token may have expired or been revoked
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("token refresh failed: %w", tokenEndpointError(resp, respBytes))
+	}
+
 	// parse tokens
 	var tokenObject appTokens
 	if err := json.Unmarshal(respBytes, &tokenObject); err != nil {
 		return fmt.Errorf("failed to JSON parse the response as a token object: %w", err)
 	}
 
-	// PROBLEM, do we need to store the new refresh token and access token ?
-	// we need to return them
+	// store the new access token along with the (possibly rotated) refresh token
+	cfg.setTokens(&tokenObject)
 	return nil
 }
 
+// tokenEndpointError describes a failed response from the auth/token endpoints, including the
+// OAuth error details when the body carries them
+func tokenEndpointError(resp *http.Response, body []byte) error {
+	var errobj oauthErrorPayload
+	if err := json.Unmarshal(body, &errobj); err != nil || errobj.Error == "" {
+		return fmt.Errorf("status %q", resp.Status)
+	}
+	if errobj.ErrorDesc == "" {
+		return fmt.Errorf("status %q: %v", resp.Status, errobj.Error)
+	}
+	return fmt.Errorf("status %q: %v (%v)", resp.Status, errobj.Error, errobj.ErrorDesc)
+}
+
 func oauthURIWithSuffix(cfg *AppdClient, suffix string) string {
 	uri, err := url.JoinPath(cfg.URL, "auth", cfg.Tenant, oauth2ClientID, suffix)
 	if err != nil {
@@ -231,49 +258,85 @@ func oauthURIWithSuffix(cfg *AppdClient, suffix string) string {
 	return uri
 }
 
-func getAuthorizationCodes(uri string) (*authCodes, error) {
-	// start http server to receive the auth callback
-	callbackServer, respChan, err := startCallbackServer()
-	if err != nil {
-		return nil, fmt.Errorf("could not start a local http server for auth: %v", err.Error())
-	}
-	defer func() {
-		_ = stopCallbackServer(callbackServer) // no check needed, error should be logged
-	}()
+// errNoBrowser is returned by getAuthorizationCodes when the browser flow is not possible on this machine
+var errNoBrowser = errors.New("the browser login is not available")
 
-	if err = openBrowser(uri); err != nil {
-		log.Errorf("Failed to automatically launch browser auth window: %v", err)
-		log.Errorf("Please visit the following URL to login\n%v\n", uri)
+// callbackServer is the local http server receiving the OAuth callback once the user logged in
+type callbackServer struct {
+	server      *http.Server
+	redirectURI string // where the platform redirects the browser, on the address actually listened on
+	respChan    chan callbackResult
+}
+
+// callbackResult is the outcome of the login received by the callback server
+type callbackResult struct {
+	codes authCodes
+	err   error
+}
+
+func (ac *AppdClient) getAuthorizationCodes(ctx context.Context, callback *callbackServer, uri string) (*authCodes, error) {
+	openBrowser := ac.OpenBrowser
+	if openBrowser == nil {
+		openBrowser = openSystemBrowser
+	}
+	if err := openBrowser(uri); err != nil {
+		return nil, fmt.Errorf("%w: failed to automatically launch browser auth window: %w", errNoBrowser, err)
 	}
 
-	authCode := <-respChan // nb: blocks until a callback is received on localhost with the correct path
+	timeout := ac.LoginTimeout
+	if timeout <= 0 {
+		timeout = DefaultLoginTimeout
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
 
-	return &authCode, nil
+	// nb: blocks until a callback is received on the correct path, the login timed out or was cancelled
+	select {
+	case result := <-callback.respChan:
+		if result.err != nil {
+			return nil, result.err
+		}
+		return &result.codes, nil
+	case <-timer.C:
+		return nil, fmt.Errorf("the login in the browser was not completed within %v", timeout)
+	case <-ctx.Done():
+		return nil, fmt.Errorf("login aborted while waiting for the browser callback: %w", ctx.Err())
+	}
 }
 
-func startCallbackServer() (*http.Server, chan authCodes, error) {
-	// construct a channel for the response
-	respChan := make(chan authCodes)
+// startCallbackServer listens on CallbackAddr, picking a free port if its port is 0
+func (ac *AppdClient) startCallbackServer() (*callbackServer, error) {
+	addr := ac.CallbackAddr
+	if addr == "" {
+		addr = DefaultOAuthCallbackAddr
+	}
 
-	// start server at oauthRedirectUri
-	urlStruct, err := url.Parse(oauthRedirectURI)
+	// listen right away so that an address already in use is reported before opening the browser
+	listener, err := net.Listen("tcp", addr)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to parse callback URL %q: %w", oauthRedirectURI, err)
+		return nil, fmt.Errorf("failed to listen on %v: %w", addr, err)
+	}
+
+	// construct a channel for the response; buffered so the handler never blocks if the login was aborted
+	callback := &callbackServer{
+		redirectURI: "http://" + listener.Addr().String() + oauthCallbackPath,
+		respChan:    make(chan callbackResult, 1),
 	}
-	server := &http.Server{
-		Addr: urlStruct.Host,
+	callback.server = &http.Server{
+		Addr: listener.Addr().String(),
 		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			callbackHandler(respChan, w, r)
+			callbackHandler(callback.respChan, w, r)
 		}),
 		ReadHeaderTimeout: 5 * time.Second,
 	}
 	go func() {
-		err := server.ListenAndServe()
-		if err != nil && err.Error() != "http: Server closed" {
-			log.Errorf("Failed to start auth http server on %v: %v", server.Addr, err)
+		err := callback.server.Serve(listener)
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Errorf("Auth http server on %v failed: %v", callback.server.Addr, err)
 		}
 	}()
-	return server, respChan, nil
+	log.Infof("Started the auth http server on %v", callback.server.Addr)
+	return callback, nil
 }
 
 func stopCallbackServer(server *http.Server) error {
@@ -287,41 +350,76 @@ func stopCallbackServer(server *http.Server) error {
 	return nil
 }
 
-func callbackHandler(respChan chan authCodes, w http.ResponseWriter, r *http.Request) {
-	// compute expected response path
-	respURI, err := url.Parse(oauthRedirectURI)
-	if err != nil {
-		log.Errorf("Unexpected failure to obtain expected callback path (likely a bug): %v", err.Error())
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
-		return
-	}
-	callbackPath := respURI.Path
-
-	uri, err := url.Parse(r.RequestURI)
-	if err != nil {
-		log.Errorf("Unexpected failure to parse callback path received (malformed request?): %v", err.Error())
-		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
-		return
-	}
-
-	// reject all requests except the callback
-	if uri.Path != callbackPath {
-		log.Infof("Failing unexpected request for %q", uri.Path)
+func callbackHandler(respChan chan callbackResult, w http.ResponseWriter, r *http.Request) {
+	// reject all requests except the callback (e.g. the favicon)
+	if r.URL.Path != oauthCallbackPath {
+		log.Infof("Failing unexpected request for %q", r.URL.Path)
 		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
 		return
 	}
 
-	values := uri.Query()
+	values := r.URL.Query()
 
-	codes := authCodes{
-		Code:  safeExtractFirstValue(values, "code"),
-		Scope: safeExtractFirstValue(values, "scope"),
-		State: safeExtractFirstValue(values, "state"),
+	var result callbackResult
+	if errorID := values.Get("error"); errorID != "" {
+		// e.g. access_denied when the user declined, see RFC 6749 section 4.1.2.1
+		result.err = fmt.Errorf("the authorization server returned %q", errorID)
+		if desc := values.Get("error_description"); desc != "" {
+			result.err = fmt.Errorf("the authorization server returned %q: %v", errorID, desc)
+		}
+		renderCallbackPage(w, true, "Login failed", result.err.Error()+". Close this window and try again.")
+	} else {
+		result.codes = authCodes{
+			Code:  safeExtractFirstValue(values, "code"),
+			Scope: safeExtractFirstValue(values, "scope"),
+			State: safeExtractFirstValue(values, "state"),
+		}
+		renderCallbackPage(w, false, "Login successful", "You can close this browser window and return to Terraform.")
 	}
 
-	fmt.Fprint(w, "Login successful. You can close this browser window.")
+	// only the first callback counts, e.g. a page reload must not block the handler
+	select {
+	case respChan <- result:
+	default:
+		log.Infof("Ignoring a repeated auth callback")
+	}
+}
 
-	respChan <- codes
+// callbackPage is shown in the browser once the login completed
+var callbackPage = template.Must(template.New("callback").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; background: #f4f5f7; color: #1f2328; }
+main { max-width: 32rem; margin: 15vh auto; padding: 2rem; background: #fff; border-radius: 8px;
+	box-shadow: 0 2px 8px rgba(0, 0, 0, .1); text-align: center; }
+h1 { font-size: 1.5rem; color: {{if .Failed}}#c62828{{else}}#049fd9{{end}}; }
+</style>
+</head>
+<body>
+<main>
+<h1>{{.Title}}</h1>
+<p>{{.Message}}</p>
+</main>
+</body>
+</html>
+`))
+
+func renderCallbackPage(w http.ResponseWriter, failed bool, title, message string) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if failed {
+		w.WriteHeader(http.StatusBadRequest)
+	}
+	err := callbackPage.Execute(w, struct {
+		Title   string
+		Message string
+		Failed  bool
+	}{title, message, failed})
+	if err != nil {
+		log.Warnf("Failed to render the auth callback page: %v", err)
+	}
 }
 
 func safeExtractFirstValue(queryValues url.Values, field string) string {
@@ -348,10 +446,10 @@ func safeExtractFirstValue(queryValues url.Values, field string) string {
 	return qv[0]
 }
 
-// openBrowser opens a browser window at the provided url. It also captures stdout message displayed
+// openSystemBrowser opens a browser window at the provided url. It also captures stdout message displayed
 // by the command (if any: xdg-open in Linux says things like "Opening in existing browser session.") so
 // that our stdout is not polluted (as it may be being captured for yaml/json parsing)
-func openBrowser(uri string) error {
+func openSystemBrowser(uri string) error {
 	// redirect browser's package stdout to a pipe, saving the original stdout
 	orig := browser.Stdout
 	r, w, _ := os.Pipe()
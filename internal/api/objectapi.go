@@ -7,86 +7,93 @@
 package api
 
 import (
-	"bytes"
-	"fmt"
-	"io"
+	"context"
+	"encoding/json"
 	"net/http"
+	"path"
 )
 
 // CreateObject is a method used to POST the knowledge store object
 // based on the fullyQualifiedTypeName with the payload set in the body
 // layerID which will be the tenant and layerType (TENANT/SOLUTION/...)
-func (ac *AppdClient) CreateObject(fullyQualifiedTypeName, layerID, layerType string, body []byte) error {
+// It returns the ID of the created object and its version (ETag), either empty if the knowledge store
+// did not report it
+// Failed requests are reported as *APIError
+func (ac *AppdClient) CreateObject(ctx context.Context, fullyQualifiedTypeName, layerID, layerType string, body []byte) (
+	objectID, etag string, err error) {
 	url := ac.URL + objectAPIPath + fullyQualifiedTypeName
 
-	bodyReader := bytes.NewReader(body)
-	req, err := http.NewRequest(http.MethodPost, url, bodyReader) //nolint:noctx // To be removed in the future
+	req, err := ac.newAPIRequest(ctx, http.MethodPost, url, body)
 	if err != nil {
-		return fmt.Errorf("failed to create a request for %q: %w", url, err)
+		return "", "", err
 	}
-
-	// Add headers
-	req.Header.Add("Content-Type", jsonContentType)
-	req.Header.Add("Accept", jsonContentType)
-	req.Header.Add("Authorization", "Bearer "+ac.Token)
-
-	req.Header.Add("layer-id", layerID)
-	req.Header.Add("layer-type", layerType)
+	setLayerHeaders(req, layerID, layerType)
 
 	// Do request
-	resp, err := ac.APIClient.Do(req)
+	resp, respBytes, err := ac.doRequest(req)
 	if err != nil {
-		return fmt.Errorf("%v request to %q failed: %w", http.MethodPost, req.URL.String(), err)
+		return "", "", err
 	}
 
-	defer resp.Body.Close()
+	return createdObjectID(resp, respBytes), resp.Header.Get("ETag"), nil
+}
 
-	if resp.StatusCode/100 != 2 {
-		return fmt.Errorf("failed to POST request to %q (status %v): %s", req.URL.String(), resp.StatusCode, resp.Status)
+// createdObjectID returns the ID the knowledge store assigned to a created object, taken from the
+// returned object or else from the Location header, empty if neither is available
+func createdObjectID(resp *http.Response, body []byte) string {
+	var created struct {
+		ID string `json:"id"`
 	}
-
-	return nil
+	if json.Unmarshal(body, &created) == nil && created.ID != "" {
+		return created.ID
+	}
+	if location := resp.Header.Get("Location"); location != "" {
+		return path.Base(location)
+	}
+	return ""
 }
 
 // UpdateObject is a method used to PUT the knowledge store object
 // based on the fullyQualifiedTypeName with the payload set in the body
 // layerID which will be the tenant and layerType (TENANT/SOLUTION/...)
-func (ac *AppdClient) UpdateObject(fullyQualifiedTypeName, objectID, layerID, layerType string, body []byte) error {
+// Pass IfMatch(etag) to only update the object if it was not modified since it was read
+// It returns the new version (ETag) of the object, empty if the knowledge store did not report it
+// Failed requests are reported as *APIError, use IsPreconditionFailed to detect a concurrent modification
+func (ac *AppdClient) UpdateObject(ctx context.Context, fullyQualifiedTypeName, objectID, layerID, layerType string, body []byte,
+	opts ...RequestOption) (string, error) {
 	url := ac.URL + objectAPIPath + fullyQualifiedTypeName + "/" + objectID
-	bodyReader := bytes.NewReader(body)
-	req, err := http.NewRequest(http.MethodPut, url, bodyReader)
+
+	req, err := ac.newAPIRequest(ctx, http.MethodPut, url, body)
 	if err != nil {
-		return fmt.Errorf("failed to create a request for %q: %w", url, err)
+		return "", err
 	}
-
-	// Add headers
-	req.Header.Add("Content-Type", jsonContentType)
-	req.Header.Add("Accept", jsonContentType)
-	req.Header.Add("Authorization", "Bearer "+ac.Token)
-
-	req.Header.Add("layer-id", layerID)
-	req.Header.Add("layer-type", layerType)
+	setLayerHeaders(req, layerID, layerType)
+	applyRequestOptions(req, opts)
 
 	// Do request
-	resp, err := ac.APIClient.Do(req)
+	resp, _, err := ac.doRequest(req)
 	if err != nil {
-		return fmt.Errorf("%v request to %q failed: %w", http.MethodPut, req.URL.String(), err)
+		return "", err
 	}
-
-	defer resp.Body.Close()
-
-	if resp.StatusCode/100 != 2 {
-		return fmt.Errorf("failed to PUT request to %q (status %v): %s", req.URL.String(), resp.StatusCode, resp.Status)
-	}
-
-	return nil
+	return resp.Header.Get("ETag"), nil
 }
 
 // GetObject is a method used to GET the knowledge store object
 // based on the fullyQualifiedTypeName and objectID
 // layerID which will be the tenant and layerType (TENANT/SOLUTION/...)
-// If objectID ie an empty string this will result in a list of objects being returned
-func (ac *AppdClient) GetObject(fullyQualifiedTypeName, objectID, layerID, layerType string) ([]byte, error) {
+// If objectID ie an empty string this will result in the first page of the list of objects being returned,
+// use ListObjects to walk all objects of a type
+// Failed requests are reported as *APIError, use IsNotFound to detect a missing object
+func (ac *AppdClient) GetObject(ctx context.Context, fullyQualifiedTypeName, objectID, layerID, layerType string) ([]byte, error) {
+	respBytes, _, err := ac.GetObjectWithETag(ctx, fullyQualifiedTypeName, objectID, layerID, layerType)
+	return respBytes, err
+}
+
+// GetObjectWithETag is like GetObject but also returns the object version (ETag) reported by the
+// knowledge store, to be passed back with IfMatch on UpdateObject and DeleteObject. The version is
+// empty if the knowledge store did not report one.
+func (ac *AppdClient) GetObjectWithETag(ctx context.Context, fullyQualifiedTypeName, objectID, layerID, layerType string) (
+	[]byte, string, error) {
 	var url string
 	if objectID == "" {
 		url = ac.URL + objectAPIPath + fullyQualifiedTypeName
@@ -94,62 +101,43 @@ func (ac *AppdClient) GetObject(fullyQualifiedTypeName, objectID, layerID, layer
 		url = ac.URL + objectAPIPath + fullyQualifiedTypeName + "/" + objectID
 	}
 
-	req, err := http.NewRequest(http.MethodGet, url, http.NoBody) //nolint:noctx // To be removed in the future
+	req, err := ac.newAPIRequest(ctx, http.MethodGet, url, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create a request for %q: %w", url, err)
+		return nil, "", err
 	}
-
-	// Add headers
-	req.Header.Add("Content-Type", jsonContentType)
-	req.Header.Add("Accept", jsonContentType)
-	req.Header.Add("Authorization", "Bearer "+ac.Token)
-
-	req.Header.Add("layer-id", layerID)
-	req.Header.Add("layer-type", layerType)
+	setLayerHeaders(req, layerID, layerType)
 
 	// Do request
-	resp, err := ac.APIClient.Do(req)
+	resp, respBytes, err := ac.doRequest(req)
 	if err != nil {
-		return nil, fmt.Errorf("%v request to %q failed: %w", http.MethodGet, req.URL.String(), err)
+		return nil, "", err
 	}
 
-	// Unmarshal the contents
-	var respBytes []byte
-	defer resp.Body.Close()
-
-	respBytes, err = io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed reading response to %v to %q (status %v): %w", http.MethodGet, req.URL.String(), resp.StatusCode, err)
-	}
-
-	return respBytes, nil
+	return respBytes, resp.Header.Get("ETag"), nil
 }
 
 // DeleteObject is a method used to DELETE the knowledge store object
 // based on the fullyQualifiedTypeName and objectID
 // layerID which will be the tenant and layerType (TENANT/SOLUTION/...)
-func (ac *AppdClient) DeleteObject(fullyQualifiedTypeName, objectID, layerID, layerType string) error {
+// Pass IfMatch(etag) to only delete the object if it was not modified since it was read
+// Failed requests are reported as *APIError, use IsNotFound to detect an already deleted object
+func (ac *AppdClient) DeleteObject(ctx context.Context, fullyQualifiedTypeName, objectID, layerID, layerType string,
+	opts ...RequestOption) error {
 	url := ac.URL + objectAPIPath + fullyQualifiedTypeName + "/" + objectID
 
-	req, err := http.NewRequest(http.MethodDelete, url, http.NoBody) //nolint:noctx // To be removed in the future
+	req, err := ac.newAPIRequest(ctx, http.MethodDelete, url, nil)
 	if err != nil {
-		return fmt.Errorf("failed to delete a request for %q: %w", url, err)
+		return err
 	}
+	setLayerHeaders(req, layerID, layerType)
+	applyRequestOptions(req, opts)
 
-	// Add headers
-	req.Header.Add("Content-Type", jsonContentType)
-	req.Header.Add("Accept", jsonContentType)
-	req.Header.Add("Authorization", "Bearer "+ac.Token)
+	// Do request
+	_, _, err = ac.doRequest(req)
+	return err
+}
 
+func setLayerHeaders(req *http.Request, layerID, layerType string) {
 	req.Header.Add("layer-id", layerID)
 	req.Header.Add("layer-type", layerType)
-
-	// Do request
-	resp, err := ac.APIClient.Do(req)
-	if err != nil {
-		return fmt.Errorf("%v request to %q failed: %w", http.MethodDelete, req.URL.String(), err)
-	}
-
-	resp.Body.Close()
-	return nil
 }
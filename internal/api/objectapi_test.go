@@ -9,6 +9,7 @@
 package api_test
 
 import (
+	"context"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -91,7 +92,7 @@ func TestCRUDObject(t *testing.T) {
 	t.Run("CreateObject", func(t *testing.T) {
 		// Call the CreateObject method
 
-		err := ac.CreateObject(sampleObjectType, sampleLayerID, sampleLayerType,
+		_, _, err := ac.CreateObject(context.Background(), sampleObjectType, sampleLayerID, sampleLayerType,
 			[]byte(`{"cloudType": "AWS", "connectionName": "just-terraform-testing", "region": "us-east-2"}`))
 
 		// Check for any errors
@@ -103,7 +104,7 @@ func TestCRUDObject(t *testing.T) {
 	// Run subtest GetObject
 	t.Run("GetObjectBeforeUpdate", func(t *testing.T) {
 		// Call the UpdateObject method
-		response, err := ac.GetObject(sampleObjectType, sampleObjectID, sampleLayerID, sampleLayerType)
+		response, err := ac.GetObject(context.Background(), sampleObjectType, sampleObjectID, sampleLayerID, sampleLayerType)
 
 		// Check for any errors
 		if err != nil {
@@ -121,7 +122,7 @@ func TestCRUDObject(t *testing.T) {
 	// Run subtest for UpdateObject
 	t.Run("UpdateObject", func(t *testing.T) {
 		// Call the UpdateObject method
-		err := ac.UpdateObject(sampleObjectType, sampleObjectID, sampleLayerID, sampleLayerType,
+		_, err := ac.UpdateObject(context.Background(), sampleObjectType, sampleObjectID, sampleLayerID, sampleLayerType,
 			[]byte(`{"cloudType": "GCP", "connectionName": "just-terraform-testing", "region": "us-west-2"}`))
 
 		// Check for any errors
@@ -141,7 +142,7 @@ func TestCRUDObject(t *testing.T) {
 	// Run subtest GetObject
 	t.Run("GetObjectAfterUpdate", func(t *testing.T) {
 		// Call the UpdateObject method
-		response, err := ac.GetObject(sampleObjectType, sampleObjectID, sampleLayerID, sampleLayerType)
+		response, err := ac.GetObject(context.Background(), sampleObjectType, sampleObjectID, sampleLayerID, sampleLayerType)
 
 		// Check for any errors
 		if err != nil {
@@ -159,7 +160,7 @@ func TestCRUDObject(t *testing.T) {
 	// Run subtest DeleteObject
 	t.Run("DeleteObject", func(t *testing.T) {
 		// Call the UpdateObject method
-		err := ac.DeleteObject(sampleObjectType, sampleObjectID, sampleLayerID, sampleLayerType)
+		err := ac.DeleteObject(context.Background(), sampleObjectType, sampleObjectID, sampleLayerID, sampleLayerType)
 
 		// Check for any errors
 		if err != nil {
@@ -174,3 +175,110 @@ func TestCRUDObject(t *testing.T) {
 		}
 	})
 }
+
+func TestObjectETag(t *testing.T) {
+	const currentETag, updatedETag = `"v2"`, `"v3"`
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			w.Header().Set("ETag", currentETag)
+			_, _ = w.Write([]byte(`{"id": "test"}`))
+		case http.MethodPut, http.MethodDelete:
+			// reject writes based on an outdated version
+			if ifMatch := r.Header.Get("If-Match"); ifMatch != "" && ifMatch != currentETag {
+				w.WriteHeader(http.StatusPreconditionFailed)
+				return
+			}
+			if r.Method == http.MethodPut {
+				w.Header().Set("ETag", updatedETag)
+			}
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer srv.Close()
+
+	ac := &api.AppdClient{
+		URL:       srv.URL,
+		APIClient: srv.Client(),
+		Token:     token,
+	}
+	ctx := context.Background()
+
+	_, etag, err := ac.GetObjectWithETag(ctx, sampleObjectType, sampleObjectID, sampleLayerID, sampleLayerType)
+	if err != nil {
+		t.Fatalf("GetObjectWithETag returned an unexpected error: %v", err)
+	}
+	if etag != currentETag {
+		t.Errorf("Got ETag %s, expected %s", etag, currentETag)
+	}
+
+	// matching version, the new version is reported by the write itself
+	newETag, err := ac.UpdateObject(ctx, sampleObjectType, sampleObjectID, sampleLayerID, sampleLayerType, []byte(`{}`), api.IfMatch(etag))
+	if err != nil {
+		t.Errorf("UpdateObject returned an unexpected error: %v", err)
+	}
+	if newETag != updatedETag {
+		t.Errorf("UpdateObject returned ETag %s, expected %s", newETag, updatedETag)
+	}
+
+	// outdated version
+	_, err = ac.UpdateObject(ctx, sampleObjectType, sampleObjectID, sampleLayerID, sampleLayerType, []byte(`{}`), api.IfMatch(`"v1"`))
+	if !api.IsPreconditionFailed(err) {
+		t.Errorf("UpdateObject returned %v, expected a precondition failed APIError", err)
+	}
+	err = ac.DeleteObject(ctx, sampleObjectType, sampleObjectID, sampleLayerID, sampleLayerType, api.IfMatch(`"v1"`))
+	if !api.IsPreconditionFailed(err) {
+		t.Errorf("DeleteObject returned %v, expected a precondition failed APIError", err)
+	}
+
+	// no known version, unconditional delete
+	err = ac.DeleteObject(ctx, sampleObjectType, sampleObjectID, sampleLayerID, sampleLayerType, api.IfMatch(""))
+	if err != nil {
+		t.Errorf("DeleteObject returned an unexpected error: %v", err)
+	}
+}
+
+func TestCreateObjectIdentity(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		location   string
+		etag       string
+		expectedID string
+	}{
+		{"IDFromBody", `{"id": "generated", "data": {}}`, "", `"v1"`, "generated"},
+		{"IDFromLocation", "", "/knowledge-store/v1/objects/" + sampleObjectType + "/located", `"v1"`, "located"},
+		{"NothingReported", "", "", "", ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				if test.location != "" {
+					w.Header().Set("Location", test.location)
+				}
+				if test.etag != "" {
+					w.Header().Set("ETag", test.etag)
+				}
+				w.WriteHeader(http.StatusCreated)
+				_, _ = w.Write([]byte(test.body))
+			}))
+			defer srv.Close()
+
+			ac := &api.AppdClient{
+				URL:       srv.URL,
+				APIClient: srv.Client(),
+				Token:     token,
+			}
+
+			objectID, etag, err := ac.CreateObject(context.Background(), sampleObjectType, sampleLayerID, sampleLayerType, []byte(`{}`))
+			if err != nil {
+				t.Fatalf("CreateObject returned an unexpected error: %v", err)
+			}
+			if objectID != test.expectedID || etag != test.etag {
+				t.Errorf("CreateObject returned ID %q with ETag %q, expected %q with %q", objectID, etag, test.expectedID, test.etag)
+			}
+		})
+	}
+}
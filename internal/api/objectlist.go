@@ -0,0 +1,84 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+//
+// SPDX-License-Identifier: MPL-2.0
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// KnowledgeObject is an object as stored in the knowledge store, together with its identity and layer
+type KnowledgeObject struct {
+	ID             string          `json:"id"`
+	LayerID        string          `json:"layerId"`
+	LayerType      string          `json:"layerType"`
+	ObjectType     string          `json:"objectType"`
+	ObjectMimeType string          `json:"objectMimeType"`
+	TargetObjectID *string         `json:"targetObjectId"`
+	Data           json.RawMessage `json:"data"` // the object payload, conforming to the type's JSON schema
+	CreatedAt      string          `json:"createdAt"`
+	UpdatedAt      string          `json:"updatedAt"`
+}
+
+// ObjectIterator walks all objects of a type, fetching further pages from the knowledge store as needed.
+//
+//	it := client.ListObjects(ctx, "fmm:namespace", tenantID, "TENANT", nil)
+//	for it.Next() {
+//		obj := it.Object()
+//		...
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type ObjectIterator struct {
+	p *pager[KnowledgeObject]
+}
+
+// ListObjects returns an iterator over the objects of fullyQualifiedTypeName visible in the given layer,
+// optionally narrowed down by query (nil lists all objects). No request is made until the first call to Next.
+func (ac *AppdClient) ListObjects(ctx context.Context, fullyQualifiedTypeName, layerID, layerType string,
+	query *ObjectQuery) *ObjectIterator {
+	url, err := query.apply(ac.URL + objectAPIPath + fullyQualifiedTypeName)
+
+	p := newPager[KnowledgeObject](ctx, ac, url, layerID, layerType)
+	p.err = err
+	if query != nil {
+		p.limit = query.limit
+	}
+	return &ObjectIterator{p: p}
+}
+
+// Next advances the iterator to the next object, returning false when there are no more objects or
+// a request failed (see Err)
+func (it *ObjectIterator) Next() bool {
+	return it.p.next()
+}
+
+// Object returns the current object; only valid after Next returned true
+func (it *ObjectIterator) Object() *KnowledgeObject {
+	obj := it.p.current
+	return &obj
+}
+
+// Total returns the total number of objects reported by the knowledge store with the last fetched page
+func (it *ObjectIterator) Total() int {
+	return it.p.total
+}
+
+// Err returns the error that stopped the iteration, if any
+func (it *ObjectIterator) Err() error {
+	return it.p.err
+}
+
+// All drains the iterator and returns all the remaining objects
+func (it *ObjectIterator) All() ([]KnowledgeObject, error) {
+	var objects []KnowledgeObject
+	for it.Next() {
+		objects = append(objects, *it.Object())
+	}
+	return objects, it.Err()
+}
@@ -0,0 +1,129 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+//
+// SPDX-License-Identifier: MPL-2.0
+
+//go:build unit
+
+package api_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cisco-open/terraform-provider-observability/internal/api"
+)
+
+const listPath = "/knowledge-store/v1/objects/" + sampleObjectType
+
+// newListServer mocks a knowledge store list endpoint serving the given pages, linked through a cursor
+func newListServer(t *testing.T, pages []string) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != listPath {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.Header.Get("layer-id") != sampleLayerID || r.Header.Get("layer-type") != sampleLayerType {
+			t.Errorf("Page request is missing the layer headers: %v", r.Header)
+		}
+
+		page := 0
+		if cursor := r.URL.Query().Get("cursor"); cursor != "" {
+			_, _ = fmt.Sscanf(cursor, "page%d", &page)
+		}
+		if page >= len(pages) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		next := ""
+		if page+1 < len(pages) {
+			next = fmt.Sprintf(`, "next": {"href": "%s?cursor=page%d"}`, listPath, page+1)
+		}
+		fmt.Fprintf(w, `{"items": [%s], "total": 3, "_links": {"self": {"href": "%s"}%s}}`, pages[page], listPath, next)
+	}))
+}
+
+func TestListObjects(t *testing.T) {
+	srv := newListServer(t, []string{
+		`{"id": "first", "layerId": "sample_tenant", "layerType": "TENANT", "objectType": "sample_object_type", "data": {"n": 1}},
+		 {"id": "second", "layerId": "sample_tenant", "layerType": "TENANT", "objectType": "sample_object_type", "data": {"n": 2}}`,
+		`{"id": "third", "layerId": "sample_tenant", "layerType": "TENANT", "objectType": "sample_object_type", "data": {"n": 3}}`,
+	})
+	defer srv.Close()
+
+	ac := &api.AppdClient{
+		URL:       srv.URL,
+		APIClient: srv.Client(),
+		Token:     token,
+	}
+
+	it := ac.ListObjects(context.Background(), sampleObjectType, sampleLayerID, sampleLayerType, nil)
+	var ids []string
+	for it.Next() {
+		obj := it.Object()
+		ids = append(ids, obj.ID)
+		if obj.LayerID != sampleLayerID || obj.LayerType != sampleLayerType {
+			t.Errorf("Object %s has unexpected layer %s/%s", obj.ID, obj.LayerType, obj.LayerID)
+		}
+		if expected := fmt.Sprintf(`{"n": %d}`, len(ids)); string(obj.Data) != expected {
+			t.Errorf("Object %s has data %s, expected %s", obj.ID, obj.Data, expected)
+		}
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("ListObjects returned an unexpected error: %v", err)
+	}
+
+	if fmt.Sprint(ids) != "[first second third]" {
+		t.Errorf("Got objects %v, expected [first second third]", ids)
+	}
+	if it.Total() != 3 {
+		t.Errorf("Got total %d, expected 3", it.Total())
+	}
+}
+
+func TestListObjectsEmpty(t *testing.T) {
+	srv := newListServer(t, []string{""})
+	defer srv.Close()
+
+	ac := &api.AppdClient{
+		URL:       srv.URL,
+		APIClient: srv.Client(),
+		Token:     token,
+	}
+
+	objects, err := ac.ListObjects(context.Background(), sampleObjectType, sampleLayerID, sampleLayerType, nil).All()
+	if err != nil {
+		t.Fatalf("ListObjects returned an unexpected error: %v", err)
+	}
+	if len(objects) != 0 {
+		t.Errorf("Got %d objects, expected none", len(objects))
+	}
+}
+
+func TestListObjectsFailure(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer srv.Close()
+
+	ac := &api.AppdClient{
+		URL:       srv.URL,
+		APIClient: srv.Client(),
+		Token:     token,
+	}
+
+	objects, err := ac.ListObjects(context.Background(), sampleObjectType, sampleLayerID, sampleLayerType, nil).All()
+	if !api.IsForbidden(err) {
+		t.Errorf("ListObjects returned %v, expected a forbidden APIError", err)
+	}
+	if len(objects) != 0 {
+		t.Errorf("Got %d objects, expected none", len(objects))
+	}
+}
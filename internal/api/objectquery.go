@@ -0,0 +1,152 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+//
+// SPDX-License-Identifier: MPL-2.0
+
+package api
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// FilterOperator is a comparison operator supported by knowledge store filter expressions
+type FilterOperator string
+
+// filter operators
+const (
+	OpEqual          FilterOperator = "eq"
+	OpNotEqual       FilterOperator = "ne"
+	OpGreaterThan    FilterOperator = "gt"
+	OpGreaterOrEqual FilterOperator = "ge"
+	OpLessThan       FilterOperator = "lt"
+	OpLessOrEqual    FilterOperator = "le"
+	OpContains       FilterOperator = "co"
+	OpStartsWith     FilterOperator = "sw"
+)
+
+// SortOrder is the direction in which listed objects are sorted
+type SortOrder string
+
+// sort orders
+const (
+	SortAscending  SortOrder = "asc"
+	SortDescending SortOrder = "desc"
+)
+
+// knowledge store query parameters
+const (
+	queryParamFilter = "filter"
+	queryParamSortBy = "sortBy"
+	queryParamOrder  = "order"
+	queryParamMax    = "max"
+	queryParamFields = "fields"
+)
+
+// ObjectQuery narrows down, orders and projects the objects returned by ListObjects. All the work is
+// done server-side by the knowledge store; a nil query lists every object of the type.
+//
+//	query := api.NewObjectQuery().
+//		Where("region", api.OpEqual, "us-east-2").
+//		SortBy("connectionName", api.SortAscending).
+//		Fields("connectionName", "region").
+//		Limit(10)
+type ObjectQuery struct {
+	filters []string
+	sortBy  string
+	order   SortOrder
+	limit   int
+	fields  []string
+}
+
+// NewObjectQuery returns an empty query
+func NewObjectQuery() *ObjectQuery {
+	return &ObjectQuery{}
+}
+
+// Where adds a condition on a field of the object data (e.g. "region" or "config.name"); all the
+// conditions of a query must match. String values are quoted, other values are used verbatim.
+func (q *ObjectQuery) Where(field string, op FilterOperator, value any) *ObjectQuery {
+	return q.Filter(fmt.Sprintf("%s %s %s", dataFieldPath(field), op, filterValue(value)))
+}
+
+// Filter adds a raw knowledge store filter expression, e.g. `data.region eq "us-east-2"`
+func (q *ObjectQuery) Filter(expression string) *ObjectQuery {
+	q.filters = append(q.filters, expression)
+	return q
+}
+
+// SortBy orders the objects by a field of the object data
+func (q *ObjectQuery) SortBy(field string, order SortOrder) *ObjectQuery {
+	q.sortBy = dataFieldPath(field)
+	q.order = order
+	return q
+}
+
+// Limit caps the number of objects returned, 0 means no limit
+func (q *ObjectQuery) Limit(maxResults int) *ObjectQuery {
+	q.limit = maxResults
+	return q
+}
+
+// Fields restricts the object data returned to the given fields
+func (q *ObjectQuery) Fields(fields ...string) *ObjectQuery {
+	for _, f := range fields {
+		q.fields = append(q.fields, dataFieldPath(f))
+	}
+	return q
+}
+
+// apply adds the query parameters to the list URL
+func (q *ObjectQuery) apply(listURL string) (string, error) {
+	if q == nil {
+		return listURL, nil
+	}
+
+	u, err := url.Parse(listURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse list URL %q: %w", listURL, err)
+	}
+
+	values := u.Query()
+	if len(q.filters) > 0 {
+		values.Set(queryParamFilter, strings.Join(q.filters, " and "))
+	}
+	if q.sortBy != "" {
+		values.Set(queryParamSortBy, q.sortBy)
+		if q.order != "" {
+			values.Set(queryParamOrder, string(q.order))
+		}
+	}
+	if q.limit > 0 {
+		values.Set(queryParamMax, strconv.Itoa(q.limit))
+	}
+	if len(q.fields) > 0 {
+		values.Set(queryParamFields, strings.Join(q.fields, ","))
+	}
+	u.RawQuery = values.Encode()
+
+	return u.String(), nil
+}
+
+// dataFieldPath qualifies a field of the object payload, accepting already qualified paths as well
+func dataFieldPath(field string) string {
+	if field == "id" || strings.HasPrefix(field, "data.") {
+		return field
+	}
+	return "data." + field
+}
+
+func filterValue(value any) string {
+	switch v := value.(type) {
+	case string:
+		return strconv.Quote(v)
+	case fmt.Stringer:
+		return strconv.Quote(v.String())
+	default:
+		return fmt.Sprint(v)
+	}
+}
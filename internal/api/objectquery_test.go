@@ -0,0 +1,92 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+//
+// SPDX-License-Identifier: MPL-2.0
+
+//go:build unit
+
+package api_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/cisco-open/terraform-provider-observability/internal/api"
+)
+
+func TestObjectQuery(t *testing.T) {
+	var received url.Values
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.URL.Query()
+		fmt.Fprint(w, `{"items": [{"id": "a"}, {"id": "b"}, {"id": "c"}], "total": 3}`)
+	}))
+	defer srv.Close()
+
+	ac := &api.AppdClient{
+		URL:       srv.URL,
+		APIClient: srv.Client(),
+		Token:     token,
+	}
+
+	query := api.NewObjectQuery().
+		Where("region", api.OpEqual, "us-east-2").
+		Where("data.port", api.OpGreaterThan, 8080).
+		Filter(`id sw "conn"`).
+		SortBy("connectionName", api.SortDescending).
+		Fields("connectionName", "region").
+		Limit(2)
+
+	objects, err := ac.ListObjects(context.Background(), sampleObjectType, sampleLayerID, sampleLayerType, query).All()
+	if err != nil {
+		t.Fatalf("ListObjects returned an unexpected error: %v", err)
+	}
+
+	expected := map[string]string{
+		"filter": `data.region eq "us-east-2" and data.port gt 8080 and id sw "conn"`,
+		"sortBy": "data.connectionName",
+		"order":  "desc",
+		"max":    "2",
+		"fields": "data.connectionName,data.region",
+	}
+	for param, value := range expected {
+		if got := received.Get(param); got != value {
+			t.Errorf("Got query parameter %s=%q, expected %q", param, got, value)
+		}
+	}
+
+	// the limit also applies when the platform returns more objects than requested
+	if len(objects) != 2 {
+		t.Errorf("Got %d objects, expected 2", len(objects))
+	}
+}
+
+func TestNilObjectQuery(t *testing.T) {
+	var rawQuery string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rawQuery = r.URL.RawQuery
+		fmt.Fprint(w, `{"items": [{"id": "a"}], "total": 1}`)
+	}))
+	defer srv.Close()
+
+	ac := &api.AppdClient{
+		URL:       srv.URL,
+		APIClient: srv.Client(),
+		Token:     token,
+	}
+
+	objects, err := ac.ListObjects(context.Background(), sampleObjectType, sampleLayerID, sampleLayerType, nil).All()
+	if err != nil {
+		t.Fatalf("ListObjects returned an unexpected error: %v", err)
+	}
+	if rawQuery != "" {
+		t.Errorf("Got query %q, expected none", rawQuery)
+	}
+	if len(objects) != 1 {
+		t.Errorf("Got %d objects, expected 1", len(objects))
+	}
+}
@@ -0,0 +1,123 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+//
+// SPDX-License-Identifier: MPL-2.0
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// collectionPage is the envelope the knowledge store wraps around every page of a list response
+type collectionPage[T any] struct {
+	Items []T `json:"items"`
+	Total int `json:"total"`
+	Links struct {
+		Next *struct {
+			Href string `json:"href"`
+		} `json:"next"`
+	} `json:"_links"`
+}
+
+// pager walks a knowledge store collection one item at a time, fetching the pages lazily by
+// following the next links (which carry the cursor) until the last page
+type pager[T any] struct {
+	ctx       context.Context // bound at creation, like sql.Rows, so the iterator API stays simple
+	ac        *AppdClient
+	layerID   string
+	layerType string
+
+	nextURL  string // URL of the next page to fetch, empty once the last page was fetched
+	seen     map[string]bool
+	items    []T
+	current  T
+	total    int
+	limit    int // maximum number of items to return, 0 for all
+	returned int
+	err      error
+}
+
+func newPager[T any](ctx context.Context, ac *AppdClient, firstURL, layerID, layerType string) *pager[T] {
+	return &pager[T]{
+		ctx:       ctx,
+		ac:        ac,
+		layerID:   layerID,
+		layerType: layerType,
+		nextURL:   firstURL,
+		seen:      make(map[string]bool),
+	}
+}
+
+// next advances to the next item, fetching a new page when the current one is exhausted
+func (p *pager[T]) next() bool {
+	if p.limit > 0 && p.returned >= p.limit {
+		return false
+	}
+
+	for len(p.items) == 0 {
+		if p.err != nil || p.nextURL == "" {
+			return false
+		}
+		p.err = p.fetch()
+	}
+
+	p.current = p.items[0]
+	p.items = p.items[1:]
+	p.returned++
+	return true
+}
+
+func (p *pager[T]) fetch() error {
+	pageURL := p.nextURL
+	if p.seen[pageURL] {
+		return fmt.Errorf("pagination loop detected, page %q was already fetched", pageURL)
+	}
+	p.seen[pageURL] = true
+
+	req, err := p.ac.newAPIRequest(p.ctx, http.MethodGet, pageURL, nil)
+	if err != nil {
+		return err
+	}
+	if p.layerID != "" || p.layerType != "" {
+		setLayerHeaders(req, p.layerID, p.layerType)
+	}
+
+	_, respBytes, err := p.ac.doRequest(req)
+	if err != nil {
+		return err
+	}
+
+	var page collectionPage[T]
+	if err := json.Unmarshal(respBytes, &page); err != nil {
+		return fmt.Errorf("failed to parse the list response from %q: %w", pageURL, err)
+	}
+
+	p.items = page.Items
+	p.total = page.Total
+	p.nextURL = ""
+	if page.Links.Next != nil && page.Links.Next.Href != "" {
+		if p.nextURL, err = resolveLink(pageURL, page.Links.Next.Href); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolveLink resolves a (possibly relative) link returned by the platform against the page it was found in
+func resolveLink(base, href string) (string, error) {
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse page URL %q: %w", base, err)
+	}
+	ref, err := url.Parse(href)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse next page link %q: %w", href, err)
+	}
+	return baseURL.ResolveReference(ref).String(), nil
+}
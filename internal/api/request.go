@@ -0,0 +1,119 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+//
+// SPDX-License-Identifier: MPL-2.0
+
+package api
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/apex/log"
+)
+
+// RequestOption customizes a single API request, e.g. with conditional headers
+type RequestOption func(req *http.Request)
+
+// IfMatch makes the request conditional on the object still being at the given version (ETag),
+// the platform rejects it with 412 Precondition Failed otherwise. An empty etag adds no condition.
+func IfMatch(etag string) RequestOption {
+	return func(req *http.Request) {
+		if etag != "" {
+			req.Header.Set("If-Match", etag)
+		}
+	}
+}
+
+func applyRequestOptions(req *http.Request, opts []RequestOption) {
+	for _, opt := range opts {
+		opt(req)
+	}
+}
+
+// newAPIRequest creates a request to the platform API with the common JSON headers set; the
+// authorization header is added by doRequest when the request is sent
+func (ac *AppdClient) newAPIRequest(ctx context.Context, method, url string, body []byte) (*http.Request, error) {
+	var bodyReader io.Reader = http.NoBody
+	if body != nil {
+		bodyReader = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, bodyReader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create a request for %q: %w", url, err)
+	}
+
+	// Add headers
+	req.Header.Add("Content-Type", jsonContentType)
+	req.Header.Add("Accept", jsonContentType)
+
+	return req, nil
+}
+
+// doRequest executes the request and returns the response together with its fully read body.
+// Transient failures are retried up to MaxRetries times (see shouldRetry) and a request rejected
+// with 401 is sent once more after refreshing the access token. Responses with a non-2xx status
+// are returned as an *APIError.
+func (ac *AppdClient) doRequest(req *http.Request) (*http.Response, []byte, error) {
+	tokenRefreshed := false
+	for attempt := 0; ; attempt++ {
+		token, err := ac.accessToken(req.Context())
+		if err != nil {
+			return nil, nil, err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		resp, respBytes, err := ac.doRequestOnce(req)
+		if IsUnauthorized(err) && !tokenRefreshed {
+			tokenRefreshed = true
+			if refreshErr := ac.refreshRejectedToken(req.Context(), token); refreshErr != nil {
+				log.Warnf("Failed to refresh the rejected access token: %v", refreshErr)
+				return resp, respBytes, err
+			}
+			if req, err = rewindRequest(req); err != nil {
+				return nil, nil, err
+			}
+			attempt-- // does not count as a retry
+			continue
+		}
+		if err == nil || attempt >= ac.MaxRetries || !shouldRetry(req, resp, err) {
+			return resp, respBytes, err
+		}
+
+		wait := retryWait(attempt, resp, ac.RetryMaxWait)
+		log.Warnf("Request %v %q failed (%v), retrying in %v (retry %d of %d)",
+			req.Method, req.URL.String(), err, wait, attempt+1, ac.MaxRetries)
+		if sleepErr := sleepContext(req.Context(), wait); sleepErr != nil {
+			return resp, respBytes, err
+		}
+
+		if req, err = rewindRequest(req); err != nil {
+			return nil, nil, err
+		}
+	}
+}
+
+// doRequestOnce executes a single attempt of the request
+func (ac *AppdClient) doRequestOnce(req *http.Request) (*http.Response, []byte, error) {
+	resp, err := ac.do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%v request to %q failed: %w", req.Method, req.URL.String(), err)
+	}
+	defer resp.Body.Close()
+
+	respBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed reading response to %v to %q (status %v): %w", req.Method, req.URL.String(), resp.StatusCode, err)
+	}
+
+	if resp.StatusCode/100 != 2 {
+		return resp, respBytes, newAPIError(resp, respBytes)
+	}
+
+	return resp, respBytes, nil
+}
@@ -0,0 +1,87 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+//
+// SPDX-License-Identifier: MPL-2.0
+
+package api
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// request limits defaults
+const (
+	DefaultMaxConcurrentRequests = 10
+	DefaultRequestTimeout        = time.Minute
+)
+
+// do sends the request with APIClient, which all the requests to the platform (login included) go through.
+// It waits for one of the MaxConcurrentRequests slots to be free and bounds the request by RequestTimeout;
+// the slot and the deadline are held until the response body is closed.
+func (ac *AppdClient) do(req *http.Request) (*http.Response, error) {
+	if err := ac.acquireRequestSlot(req.Context()); err != nil {
+		return nil, fmt.Errorf("gave up waiting for a free request slot: %w", err)
+	}
+
+	cancel := context.CancelFunc(func() {})
+	if ac.RequestTimeout > 0 {
+		var ctx context.Context
+		ctx, cancel = context.WithTimeout(req.Context(), ac.RequestTimeout)
+		req = req.WithContext(ctx)
+	}
+	release := func() {
+		cancel()
+		ac.releaseRequestSlot()
+	}
+
+	resp, err := ac.APIClient.Do(req)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	resp.Body = &releasingBody{ReadCloser: resp.Body, release: release}
+	return resp, nil
+}
+
+// acquireRequestSlot blocks until fewer than MaxConcurrentRequests requests are in flight, or ctx is done
+func (ac *AppdClient) acquireRequestSlot(ctx context.Context) error {
+	if ac.MaxConcurrentRequests <= 0 {
+		return nil
+	}
+	ac.requestSlotsOnce.Do(func() {
+		ac.requestSlots = make(chan struct{}, ac.MaxConcurrentRequests)
+	})
+
+	select {
+	case ac.requestSlots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (ac *AppdClient) releaseRequestSlot() {
+	if ac.MaxConcurrentRequests <= 0 {
+		return
+	}
+	<-ac.requestSlots
+}
+
+// releasingBody is a response body releasing the request slot and deadline when closed
+type releasingBody struct {
+	io.ReadCloser
+	release   func()
+	closeOnce sync.Once
+}
+
+func (b *releasingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.closeOnce.Do(b.release)
+	return err
+}
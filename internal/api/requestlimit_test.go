@@ -0,0 +1,104 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+//
+// SPDX-License-Identifier: MPL-2.0
+
+//go:build unit
+
+package api_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cisco-open/terraform-provider-observability/internal/api"
+)
+
+func TestMaxConcurrentRequests(t *testing.T) {
+	const maxConcurrent, requests = 2, 10
+
+	var inFlight, maxInFlight atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			highest := maxInFlight.Load()
+			if n <= highest || maxInFlight.CompareAndSwap(highest, n) {
+				break
+			}
+		}
+
+		time.Sleep(20 * time.Millisecond)
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	ac := &api.AppdClient{
+		URL:                   srv.URL,
+		APIClient:             srv.Client(),
+		Token:                 token,
+		MaxConcurrentRequests: maxConcurrent,
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := ac.GetObject(context.Background(), sampleObjectType, sampleObjectID, sampleLayerID, sampleLayerType); err != nil {
+				t.Errorf("GetObject returned an error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := maxInFlight.Load(); got > maxConcurrent {
+		t.Errorf("Got %d concurrent requests, expected at most %d", got, maxConcurrent)
+	}
+}
+
+func TestRequestTimeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	ac := &api.AppdClient{
+		URL:                   srv.URL,
+		APIClient:             srv.Client(),
+		Token:                 token,
+		RequestTimeout:        50 * time.Millisecond,
+		MaxConcurrentRequests: 1,
+	}
+
+	start := time.Now()
+	_, err := ac.GetObject(context.Background(), sampleObjectType, sampleObjectID, sampleLayerID, sampleLayerType)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("GetObject returned %v, expected a deadline exceeded error", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("GetObject took %v despite the request timeout", elapsed)
+	}
+
+	// the request slot of the timed out request was released
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, err = ac.GetObject(ctx, sampleObjectType, sampleObjectID, sampleLayerID, sampleLayerType)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("GetObject returned %v, expected the request to time out again", err)
+	}
+	if ctx.Err() != nil {
+		t.Errorf("GetObject waited for a request slot until the context was done")
+	}
+}
@@ -0,0 +1,111 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+//
+// SPDX-License-Identifier: MPL-2.0
+
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// retry related defaults
+const (
+	retryBaseWait       = 500 * time.Millisecond // delay before the first retry, doubled on every attempt
+	DefaultMaxRetries   = 3
+	DefaultRetryMaxWait = 30 * time.Second
+)
+
+// shouldRetry decides whether a failed attempt can be issued again. Idempotent methods (GET, PUT, DELETE, ...)
+// are retried on transport errors and transient statuses; POST is retried only when the platform throttled
+// the request (429), since that guarantees the object was not created.
+func shouldRetry(req *http.Request, resp *http.Response, err error) bool {
+	// never retry once the caller gave up
+	if req.Context().Err() != nil {
+		return false
+	}
+
+	idempotent := req.Method != http.MethodPost && req.Method != http.MethodPatch
+	if resp == nil {
+		// transport level failure, the request may or may not have reached the platform
+		return err != nil && idempotent
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return true
+	}
+	return idempotent && isRetryableStatus(resp.StatusCode)
+}
+
+// retryWait returns how long to wait before retrying the given (0-based) attempt. It honors the
+// Retry-After header when present, otherwise it uses exponential backoff with full jitter. The
+// result never exceeds maxWait.
+func retryWait(attempt int, resp *http.Response, maxWait time.Duration) time.Duration {
+	if maxWait <= 0 {
+		maxWait = DefaultRetryMaxWait
+	}
+
+	if resp != nil {
+		if wait, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+			return min(wait, maxWait)
+		}
+	}
+
+	backoff := retryBaseWait << attempt
+	if backoff <= 0 || backoff > maxWait {
+		backoff = maxWait // overflow or over the cap
+	}
+	return rand.N(backoff) + 1 //nolint:gosec // jitter does not require a secure random source
+}
+
+// parseRetryAfter parses the Retry-After header value, expressed either in seconds or as an HTTP date
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0), true
+	}
+	return 0, false
+}
+
+// sleepContext waits for the given duration, returning early with an error if the context is done
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// rewindRequest prepares a copy of the request that can be sent again, with a fresh body
+func rewindRequest(req *http.Request) (*http.Request, error) {
+	retryReq := req.Clone(req.Context())
+	if req.Body == nil || req.Body == http.NoBody {
+		return retryReq, nil
+	}
+	if req.GetBody == nil {
+		return nil, errors.New("request body cannot be replayed")
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, fmt.Errorf("failed to replay the request body: %w", err)
+	}
+	retryReq.Body = body
+	return retryReq, nil
+}
@@ -0,0 +1,129 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+//
+// SPDX-License-Identifier: MPL-2.0
+
+//go:build unit
+
+package api_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cisco-open/terraform-provider-observability/internal/api"
+)
+
+const samplePayload = `{"cloudType": "AWS"}`
+
+func TestRetry(t *testing.T) {
+	tests := []struct {
+		name             string
+		method           string
+		failures         []int // statuses returned before the request succeeds
+		retryAfter       string
+		maxRetries       int
+		expectedAttempts int32
+		expectedSuccess  bool
+	}{
+		{"GetRetriedOnUnavailable", http.MethodGet, []int{503, 502}, "", 3, 3, true},
+		{"PutRetriedOnThrottling", http.MethodPut, []int{429}, "0", 3, 2, true},
+		{"DeleteGivesUpAfterMaxRetries", http.MethodDelete, []int{504, 504, 504}, "", 2, 3, false},
+		{"PostRetriedOnThrottling", http.MethodPost, []int{429}, "0", 3, 2, true},
+		{"PostNotRetriedOnUnavailable", http.MethodPost, []int{503}, "", 3, 1, false},
+		{"NotRetriedOnClientError", http.MethodGet, []int{400}, "", 3, 1, false},
+		{"RetriesDisabled", http.MethodGet, []int{503}, "", 0, 1, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var attempts atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := attempts.Add(1)
+
+				// the body must be replayed on every attempt
+				if r.Method == http.MethodPost || r.Method == http.MethodPut {
+					if body, _ := io.ReadAll(r.Body); string(body) != samplePayload {
+						t.Errorf("Attempt %d received body %q, expected %q", n, body, samplePayload)
+					}
+				}
+
+				if int(n) <= len(test.failures) {
+					if test.retryAfter != "" {
+						w.Header().Set("Retry-After", test.retryAfter)
+					}
+					w.WriteHeader(test.failures[n-1])
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+				_, _ = w.Write([]byte(`{}`))
+			}))
+			defer srv.Close()
+
+			ac := &api.AppdClient{
+				URL:          srv.URL,
+				APIClient:    srv.Client(),
+				Token:        token,
+				MaxRetries:   test.maxRetries,
+				RetryMaxWait: 10 * time.Millisecond,
+			}
+
+			var err error
+			ctx := context.Background()
+			switch test.method {
+			case http.MethodGet:
+				_, err = ac.GetObject(ctx, sampleObjectType, sampleObjectID, sampleLayerID, sampleLayerType)
+			case http.MethodPut:
+				_, err = ac.UpdateObject(ctx, sampleObjectType, sampleObjectID, sampleLayerID, sampleLayerType, []byte(samplePayload))
+			case http.MethodPost:
+				_, _, err = ac.CreateObject(ctx, sampleObjectType, sampleLayerID, sampleLayerType, []byte(samplePayload))
+			case http.MethodDelete:
+				err = ac.DeleteObject(ctx, sampleObjectType, sampleObjectID, sampleLayerID, sampleLayerType)
+			}
+
+			if test.expectedSuccess && err != nil {
+				t.Errorf("Request returned an unexpected error: %v", err)
+			}
+			if !test.expectedSuccess && err == nil {
+				t.Errorf("Request succeeded but an error was expected")
+			}
+			if got := attempts.Load(); got != test.expectedAttempts {
+				t.Errorf("Got %d attempts, expected %d", got, test.expectedAttempts)
+			}
+		})
+	}
+}
+
+func TestRetryStopsWhenCancelled(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	ac := &api.AppdClient{
+		URL:          srv.URL,
+		APIClient:    srv.Client(),
+		Token:        token,
+		MaxRetries:   5,
+		RetryMaxWait: time.Minute,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := ac.GetType(ctx, testType)
+	if !api.IsRetryable(err) {
+		t.Errorf("GetType returned %v, expected the last retryable APIError", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("GetType kept retrying for %v after the context was done", elapsed)
+	}
+}
@@ -7,7 +7,9 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -16,14 +18,34 @@ import (
 	"strings"
 
 	"github.com/apex/log"
+	"gopkg.in/yaml.v3"
 )
 
 type credentialsStruct struct {
-	ClientID string `json:"Client ID"`
-	Secret   string `json:"Secret"`
+	ClientID string
+	Secret   string
 }
 
-func (ac *AppdClient) servicePrincipalLogin() error {
+// credentialsFile holds the keys of all the service principal credentials file layouts downloaded from the
+// platform UI over time: JSON with "Client ID" and "Secret", snake_case JSON, or YAML with either key style
+type credentialsFile struct {
+	ClientID          string `json:"Client ID" yaml:"Client ID"`
+	Secret            string `json:"Secret" yaml:"Secret"`
+	SnakeClientID     string `json:"client_id" yaml:"client_id"`
+	SnakeClientSecret string `json:"client_secret" yaml:"client_secret"`
+	CamelClientID     string `json:"clientId" yaml:"clientId"`
+	CamelClientSecret string `json:"clientSecret" yaml:"clientSecret"`
+}
+
+func (ac *AppdClient) servicePrincipalLogin(ctx context.Context) error {
+	// credentials set inline (e.g. injected as env vars) take precedence over the credentials file
+	if ac.ClientID != "" || ac.ClientSecret != "" {
+		if ac.ClientID == "" || ac.ClientSecret == "" {
+			return errors.New("both the client ID and the client secret of the service principal are required")
+		}
+		return servicePrincipalLogin(ctx, ac, &credentialsStruct{ClientID: ac.ClientID, Secret: ac.ClientSecret})
+	}
+
 	// read credentials file
 	file := ac.SecretFile
 	credentials, err := readJSONCredentials(file)
@@ -31,10 +53,10 @@ func (ac *AppdClient) servicePrincipalLogin() error {
 		return fmt.Errorf("failed to read credentials file %q: %w", file, err)
 	}
 
-	return servicePrincipalLogin(ac, credentials)
+	return servicePrincipalLogin(ctx, ac, credentials)
 }
 
-func servicePrincipalLogin(ac *AppdClient, credentials *credentialsStruct) error {
+func servicePrincipalLogin(ctx context.Context, ac *AppdClient, credentials *credentialsStruct) error {
 	// create a HTTP request
 	uri, err := url.Parse(ac.URL)
 	if err != nil {
@@ -42,21 +64,25 @@ func servicePrincipalLogin(ac *AppdClient, credentials *credentialsStruct) error
 	}
 	uri.Path = "auth/" + ac.Tenant + "/default/oauth2/token"
 
-	//nolint:noctx // To be removed in the future
-	req, err := http.NewRequest("POST", uri.String(), strings.NewReader("grant_type=client_credentials"))
+	return clientCredentialsLogin(ctx, ac, uri.String(), credentials.ClientID, credentials.Secret)
+}
+
+// clientCredentialsLogin exchanges a principal's client ID and secret for an access token (OAuth client
+// credentials grant) at the given token endpoint
+func clientCredentialsLogin(ctx context.Context, ac *AppdClient, tokenURI, clientID, secret string) error {
+	req, err := http.NewRequestWithContext(ctx, "POST", tokenURI, strings.NewReader("grant_type=client_credentials"))
 	if err != nil {
-		return fmt.Errorf("failed to create a request for %q: %w", uri.String(), err)
+		return fmt.Errorf("failed to create a request for %q: %w", tokenURI, err)
 	}
 
 	req.Header.Add("Accept", "application/json")
 	req.Header.Add("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
-	req.SetBasicAuth(credentials.ClientID, credentials.Secret)
+	req.SetBasicAuth(clientID, secret)
 
 	// execute request
-	client := ac.APIClient
-	resp, err := client.Do(req)
+	resp, err := ac.do(req)
 	if err != nil {
-		return fmt.Errorf("failed to request auth (%q): %w", uri.String(), err)
+		return fmt.Errorf("failed to request auth (%q): %w", tokenURI, err)
 	}
 	if resp.StatusCode != http.StatusOK {
 		log.Errorf("Login failed, status %q; details to follow", resp.Status)
@@ -66,7 +92,10 @@ func servicePrincipalLogin(ac *AppdClient, credentials *credentialsStruct) error
 	defer resp.Body.Close()
 	respBytes, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return fmt.Errorf("failed reading login response from %q: %w", uri.String(), err)
+		return fmt.Errorf("failed reading login response from %q: %w", tokenURI, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("login failed: %w", tokenEndpointError(resp, respBytes))
 	}
 
 	// update context with token
@@ -77,7 +106,7 @@ func servicePrincipalLogin(ac *AppdClient, credentials *credentialsStruct) error
 		return err
 	}
 	log.Info("Login returned a valid token")
-	ac.Token = token.AccessToken
+	ac.setTokens(&token)
 
 	return nil
 }
@@ -94,10 +123,23 @@ func readJSONCredentials(file string) (*credentialsStruct, error) {
 		return nil, fmt.Errorf("failed to read the credentials file %q: %w", file, err)
 	}
 
-	var credentials credentialsStruct
-	if err = json.Unmarshal(data, &credentials); err != nil {
-		return nil, fmt.Errorf("failed to parse credentials file %q: %w", file, err)
+	// JSON is tried first: it matches keys case-insensitively and YAML does not support all its escapes
+	var content credentialsFile
+	if jsonErr := json.Unmarshal(data, &content); jsonErr != nil {
+		content = credentialsFile{}
+		if err = yaml.Unmarshal(data, &content); err != nil {
+			return nil, fmt.Errorf("failed to parse credentials file %q: %w", file, err)
+		}
+	}
+
+	credentials := &credentialsStruct{
+		ClientID: firstNonEmpty(content.ClientID, content.SnakeClientID, content.CamelClientID),
+		Secret:   firstNonEmpty(content.Secret, content.SnakeClientSecret, content.CamelClientSecret),
+	}
+	if credentials.ClientID == "" || credentials.Secret == "" {
+		return nil, fmt.Errorf("the credentials file %q must contain a client ID and a secret "+
+			"(\"Client ID\" and \"Secret\", client_id and client_secret, or clientId and clientSecret)", file)
 	}
 
-	return &credentials, nil
+	return credentials, nil
 }
@@ -0,0 +1,113 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+//
+// SPDX-License-Identifier: MPL-2.0
+
+package api
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/apex/log"
+)
+
+// tokenExpirySkew is how long before its actual expiry an access token is considered expired, leaving
+// enough room for in-flight requests to complete with it
+const tokenExpirySkew = 60 * time.Second
+
+// setTokens stores the tokens returned by the auth endpoints; the caller must hold tokenMu
+func (ac *AppdClient) setTokens(tokens *appTokens) {
+	ac.Token = tokens.AccessToken
+	if tokens.RefreshToken != "" {
+		ac.RefreshToken = tokens.RefreshToken // refresh tokens may be rotated on every use
+	}
+
+	ac.tokenExpiry = time.Time{}
+	if tokens.ExpiresIn > 0 {
+		ac.tokenExpiry = time.Now().Add(time.Duration(tokens.ExpiresIn) * time.Second)
+	}
+
+	ac.saveCachedTokens()
+}
+
+// UseCachedTokens hands tokens obtained earlier (e.g. cached by fsoc) to the client: the next Login reuses
+// them instead of authenticating unless the access token expired, and they are refreshed like any other token
+// once about to expire or rejected. They are not written to the token cache, being managed by whoever cached them.
+func (ac *AppdClient) UseCachedTokens(accessToken, refreshToken string) {
+	ac.tokenMu.Lock()
+	defer ac.tokenMu.Unlock()
+
+	ac.Token = accessToken
+	if refreshToken != "" {
+		ac.RefreshToken = refreshToken
+	}
+
+	// opaque tokens have no known expiry, they are refreshed once rejected
+	ac.tokenExpiry = time.Time{}
+	if claims, err := parseJWTClaims(accessToken); err == nil {
+		ac.tokenExpiry = claims.expiresAt()
+	}
+	ac.cachedToken = accessToken != ""
+}
+
+// accessToken returns a valid access token, refreshing it first if it is about to expire
+func (ac *AppdClient) accessToken(ctx context.Context) (string, error) {
+	ac.tokenMu.Lock()
+	defer ac.tokenMu.Unlock()
+
+	if !ac.tokenExpiry.IsZero() && time.Now().Add(tokenExpirySkew).After(ac.tokenExpiry) {
+		log.Infof("Access token expires at %v, refreshing it", ac.tokenExpiry.Format(time.RFC3339))
+		if err := ac.refresh(ctx); err != nil {
+			return "", fmt.Errorf("access token expired and could not be refreshed: %w", err)
+		}
+	}
+
+	return ac.Token, nil
+}
+
+// refreshRejectedToken obtains a new access token after the platform rejected staleToken. Concurrent
+// callers that were rejected with the same token share a single refresh.
+func (ac *AppdClient) refreshRejectedToken(ctx context.Context, staleToken string) error {
+	ac.tokenMu.Lock()
+	defer ac.tokenMu.Unlock()
+
+	if ac.Token != staleToken {
+		return nil // already refreshed by another request
+	}
+
+	log.Infof("Access token was rejected, refreshing it")
+	return ac.refresh(ctx)
+}
+
+// refresh obtains a new access token without user interaction; the caller must hold tokenMu
+func (ac *AppdClient) refresh(ctx context.Context) error {
+	switch ac.AuthMethod {
+	case authMethodOAuth, authMethodDeviceCode:
+		if ac.RefreshToken == "" {
+			return fmt.Errorf("no refresh token available, please log in again")
+		}
+		return oauthRefreshToken(ctx, ac)
+	case headless:
+		// prefer the refresh token, fall back to the password if it expired or was never issued
+		if ac.RefreshToken != "" {
+			if err := oauthRefreshToken(ctx, ac); err == nil {
+				return nil
+			}
+		}
+		return ac.login(ctx)
+	case servicePrincipal, agentPrincipal, credentialProcess:
+		// client credentials can simply be exchanged again, and the credential process run again
+		return ac.login(ctx)
+	case authMethodAccessToken:
+		// there is nothing to refresh it with, keep using the token until it actually expires
+		if ac.tokenExpiry.IsZero() || time.Now().Before(ac.tokenExpiry) {
+			return nil
+		}
+		return fmt.Errorf("%w at %v, please provide a new one", ErrTokenExpired, ac.tokenExpiry.Format(time.RFC3339))
+	default:
+		return fmt.Errorf("access tokens cannot be refreshed for authentication method %q", ac.AuthMethod)
+	}
+}
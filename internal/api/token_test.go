@@ -0,0 +1,271 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+//
+// SPDX-License-Identifier: MPL-2.0
+
+//go:build unit
+
+package api_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cisco-open/terraform-provider-observability/internal/api"
+)
+
+const tokenPath = "/auth/" + tenant + "/default/oauth2/token"
+
+// newTokenServer mocks the token endpoint, issuing token-1, token-2, ... valid for expiresIn seconds,
+// and the type API, which rejects any token listed in rejected
+func newTokenServer(t *testing.T, expiresIn int, rejected ...string) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+
+	var logins atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == tokenPath {
+			n := logins.Add(1)
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(w, `{"access_token": "token-%d", "expires_in": %d}`, n, expiresIn)
+			return
+		}
+
+		bearer := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		for _, token := range rejected {
+			if bearer == token {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+		}
+		fmt.Fprintf(w, `{"token": %q}`, bearer)
+	}))
+	return srv, &logins
+}
+
+func newServicePrincipalClient(t *testing.T, srv *httptest.Server) *api.AppdClient {
+	t.Helper()
+
+	tmpfile, err := createTempJSONFile(payload)
+	if err != nil {
+		t.Fatalf("Failed during creation of temporary json file: %v", err)
+	}
+	t.Cleanup(func() { os.Remove(tmpfile) })
+
+	return &api.AppdClient{
+		URL:        srv.URL,
+		Tenant:     tenant,
+		AuthMethod: servicePrincipal,
+		SecretFile: tmpfile,
+		APIClient:  srv.Client(),
+	}
+}
+
+func TestTokenRefreshedBeforeExpiry(t *testing.T) {
+	// tokens expiring within the refresh skew are renewed before every request
+	srv, logins := newTokenServer(t, 30)
+	defer srv.Close()
+
+	ac := newServicePrincipalClient(t, srv)
+	if err := ac.Login(context.Background()); err != nil {
+		t.Fatalf("Login returned an error: %v", err)
+	}
+
+	response, err := ac.GetType(context.Background(), testType)
+	if err != nil {
+		t.Fatalf("GetType returned an error: %v", err)
+	}
+	if string(response) != `{"token": "token-2"}` {
+		t.Errorf("GetType was sent with an unexpected token: %s", response)
+	}
+	if got := logins.Load(); got != 2 {
+		t.Errorf("Got %d logins, expected 2", got)
+	}
+}
+
+func TestTokenRefreshedOnUnauthorized(t *testing.T) {
+	srv, logins := newTokenServer(t, 3600, "token-1")
+	defer srv.Close()
+
+	ac := newServicePrincipalClient(t, srv)
+	if err := ac.Login(context.Background()); err != nil {
+		t.Fatalf("Login returned an error: %v", err)
+	}
+
+	// all concurrent requests rejected with the same token must share a single refresh
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := ac.GetType(context.Background(), testType); err != nil {
+				t.Errorf("GetType returned an error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := logins.Load(); got != 2 {
+		t.Errorf("Got %d logins, expected 2", got)
+	}
+}
+
+func TestTokenRefreshGivesUpAfterOneAttempt(t *testing.T) {
+	srv, logins := newTokenServer(t, 3600, "token-1", "token-2")
+	defer srv.Close()
+
+	ac := newServicePrincipalClient(t, srv)
+	if err := ac.Login(context.Background()); err != nil {
+		t.Fatalf("Login returned an error: %v", err)
+	}
+
+	_, err := ac.GetType(context.Background(), testType)
+	if !api.IsUnauthorized(err) {
+		t.Errorf("GetType returned %v, expected an unauthorized APIError", err)
+	}
+	if got := logins.Load(); got != 2 {
+		t.Errorf("Got %d logins, expected 2", got)
+	}
+}
+
+func TestOAuthRefreshTokenRotated(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == tokenPath {
+			if err := r.ParseForm(); err != nil || r.PostForm.Get("refresh_token") != "refresh-1" {
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprint(w, `{"error": "invalid_grant"}`)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"access_token": "fresh", "refresh_token": "refresh-2", "expires_in": 3600}`)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer fresh" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, expectedResponse)
+	}))
+	defer srv.Close()
+
+	ac := &api.AppdClient{
+		URL:          srv.URL,
+		Tenant:       tenant,
+		AuthMethod:   oauth,
+		APIClient:    srv.Client(),
+		Token:        "stale",
+		RefreshToken: "refresh-1",
+	}
+
+	response, err := ac.GetType(context.Background(), testType)
+	if err != nil {
+		t.Fatalf("GetType returned an error: %v", err)
+	}
+	if string(response) != expectedResponse {
+		t.Errorf("GetType returned incorrect response: got %s, want %s", response, expectedResponse)
+	}
+	if ac.RefreshToken != "refresh-2" {
+		t.Errorf("Refresh token was not rotated, got %q", ac.RefreshToken)
+	}
+}
+
+func TestHeadlessRefreshFallsBackToPassword(t *testing.T) {
+	// an expired refresh token must not prevent a headless client from logging in again
+	var grants []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == tokenPath {
+			_ = r.ParseForm()
+			grants = append(grants, r.PostForm.Get("grant_type"))
+			if r.PostForm.Get("grant_type") != "password" {
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprint(w, `{"error": "invalid_grant"}`)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"access_token": "fresh", "expires_in": 3600}`)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer fresh" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, expectedResponse)
+	}))
+	defer srv.Close()
+
+	ac := &api.AppdClient{
+		URL:          srv.URL,
+		Tenant:       tenant,
+		AuthMethod:   headless,
+		Username:     username,
+		Password:     password,
+		APIClient:    srv.Client(),
+		Token:        "stale",
+		RefreshToken: "expired",
+	}
+
+	response, err := ac.GetType(context.Background(), testType)
+	if err != nil {
+		t.Fatalf("GetType returned an error: %v", err)
+	}
+	if string(response) != expectedResponse {
+		t.Errorf("GetType returned %s, expected %s", response, expectedResponse)
+	}
+	if strings.Join(grants, ",") != "refresh_token,password" {
+		t.Errorf("Got grants %v, expected a refresh followed by a password login", grants)
+	}
+}
+
+func TestCachedTokenReused(t *testing.T) {
+	// a cached token is used without logging in, and replaced by logging in again once it is rejected
+	srv, logins := newTokenServer(t, 3600, "cached")
+	defer srv.Close()
+
+	ac := newServicePrincipalClient(t, srv)
+	ac.UseCachedTokens("cached", "")
+	if err := ac.Login(context.Background()); err != nil {
+		t.Fatalf("Login returned an error: %v", err)
+	}
+	if logins.Load() != 0 {
+		t.Errorf("Login authenticated %d times despite the cached token", logins.Load())
+	}
+
+	response, err := ac.GetType(context.Background(), testType)
+	if err != nil {
+		t.Fatalf("GetType returned an error: %v", err)
+	}
+	if string(response) != `{"token": "token-1"}` {
+		t.Errorf("GetType was authorized with %s, expected token-1", response)
+	}
+}
+
+func TestCachedTokenExpired(t *testing.T) {
+	// a cached JWT which expired, or is about to, is replaced by logging in rather than reused
+	srv, logins := newTokenServer(t, 3600)
+	defer srv.Close()
+
+	ac := newServicePrincipalClient(t, srv)
+	ac.UseCachedTokens(newJWT(fmt.Sprintf(`{"exp": %d}`, time.Now().Add(-time.Minute).Unix())), "")
+	if err := ac.Login(context.Background()); err != nil {
+		t.Fatalf("Login returned an error: %v", err)
+	}
+	if logins.Load() != 1 || ac.Token != "token-1" {
+		t.Errorf("Got token %q after %d logins, expected a login replacing the expired token", ac.Token, logins.Load())
+	}
+
+	ac.UseCachedTokens(newJWT(fmt.Sprintf(`{"exp": %d}`, time.Now().Add(30*time.Second).Unix())), "")
+	if err := ac.Login(context.Background()); err != nil {
+		t.Fatalf("Login returned an error: %v", err)
+	}
+	if logins.Load() != 2 {
+		t.Errorf("Got %d logins, expected the token about to expire to be replaced", logins.Load())
+	}
+}
@@ -0,0 +1,159 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+//
+// SPDX-License-Identifier: MPL-2.0
+
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/apex/log"
+)
+
+// tokenCacheDirName is the directory created in the user cache dir to hold the token cache
+const tokenCacheDirName = "terraform-provider-observability"
+
+// cachedTokens is the content of a token cache file
+type cachedTokens struct {
+	AccessToken  string    `json:"access_token"`
+	RefreshToken string    `json:"refresh_token,omitempty"`
+	Expiry       time.Time `json:"expiry"` // zero if unknown
+}
+
+// DefaultTokenCacheDir returns the directory holding the token cache in the user cache dir
+// (e.g. ~/.cache/terraform-provider-observability on Linux)
+func DefaultTokenCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate the user cache directory: %w", err)
+	}
+	return filepath.Join(dir, tokenCacheDirName), nil
+}
+
+// tokenCacheFile returns the cache file for the client's URL, tenant and principal, empty if caching is disabled
+func (ac *AppdClient) tokenCacheFile() string {
+	if ac.TokenCacheDir == "" || ac.AuthMethod == authMethodAccessToken || ac.AuthMethod == credentialProcess {
+		return "" // tokens handed to the client are managed by whoever minted them
+	}
+
+	// the principal is whatever identifies who logs in with the configured method
+	var principal string
+	switch ac.AuthMethod {
+	case headless:
+		principal = ac.Username
+	case servicePrincipal, agentPrincipal:
+		principal = ac.principalClientID()
+		if principal == "" {
+			return "" // the login reports the unreadable credentials
+		}
+	}
+
+	key := sha256.Sum256([]byte(ac.URL + "\n" + ac.Tenant + "\n" + ac.AuthMethod + "\n" + principal))
+	return filepath.Join(ac.TokenCacheDir, hex.EncodeToString(key[:])+".json")
+}
+
+// principalClientID returns the client ID a service or agent principal logs in with, read from the credentials
+// file unless set inline, so that the cache follows the principal rather than the file it is stored in.
+// It is empty if the credentials file cannot be read.
+func (ac *AppdClient) principalClientID() string {
+	if ac.ClientID != "" {
+		return ac.ClientID
+	}
+
+	if ac.AuthMethod == agentPrincipal {
+		credentials, err := readAgentCredentials(ac.SecretFile)
+		if err != nil {
+			return ""
+		}
+		return credentials.ClientID
+	}
+	credentials, err := readJSONCredentials(ac.SecretFile)
+	if err != nil {
+		return ""
+	}
+	return credentials.ClientID
+}
+
+// loadCachedTokens restores the tokens from the token cache, reporting whether the cached access token
+// can be used as is; the caller must hold tokenMu
+func (ac *AppdClient) loadCachedTokens() bool {
+	file := ac.tokenCacheFile()
+	if file == "" {
+		return false
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Warnf("Failed to read the token cache %q: %v", file, err)
+		}
+		return false
+	}
+
+	var tokens cachedTokens
+	if err = json.Unmarshal(data, &tokens); err != nil {
+		log.Warnf("Ignoring the corrupted token cache %q: %v", file, err)
+		return false
+	}
+
+	// keep the refresh token even if the access token expired, the login flows use it to refresh silently
+	if tokens.RefreshToken != "" {
+		ac.RefreshToken = tokens.RefreshToken
+	}
+	if tokens.AccessToken == "" || (!tokens.Expiry.IsZero() && time.Now().Add(tokenExpirySkew).After(tokens.Expiry)) {
+		return false
+	}
+
+	ac.Token = tokens.AccessToken
+	ac.tokenExpiry = tokens.Expiry
+	return true
+}
+
+// saveCachedTokens writes the current tokens to the token cache, readable by the current user only;
+// failures are logged since the tokens remain usable in memory. The caller must hold tokenMu.
+func (ac *AppdClient) saveCachedTokens() {
+	file := ac.tokenCacheFile()
+	if file == "" {
+		return
+	}
+
+	data, err := json.Marshal(cachedTokens{AccessToken: ac.Token, RefreshToken: ac.RefreshToken, Expiry: ac.tokenExpiry})
+	if err != nil {
+		log.Warnf("Failed to encode the token cache: %v", err)
+		return
+	}
+
+	if err = os.MkdirAll(ac.TokenCacheDir, 0o700); err != nil {
+		log.Warnf("Failed to create the token cache directory %q: %v", ac.TokenCacheDir, err)
+		return
+	}
+
+	// write to a temporary file first so concurrent runs never read a partially written cache
+	tmp, err := os.CreateTemp(ac.TokenCacheDir, filepath.Base(file)+".*.tmp")
+	if err != nil {
+		log.Warnf("Failed to create the token cache %q: %v", file, err)
+		return
+	}
+	defer os.Remove(tmp.Name()) // no-op once renamed
+
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close()
+		log.Warnf("Failed to write the token cache %q: %v", file, err)
+		return
+	}
+	if err = tmp.Close(); err != nil {
+		log.Warnf("Failed to write the token cache %q: %v", file, err)
+		return
+	}
+	if err = os.Rename(tmp.Name(), file); err != nil {
+		log.Warnf("Failed to write the token cache %q: %v", file, err)
+	}
+}
@@ -0,0 +1,121 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+//
+// SPDX-License-Identifier: MPL-2.0
+
+//go:build unit
+
+package api_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cisco-open/terraform-provider-observability/internal/api"
+)
+
+func TestTokenCacheReused(t *testing.T) {
+	srv, logins := newTokenServer(t, 3600)
+	defer srv.Close()
+	cacheDir := t.TempDir()
+
+	ac := newServicePrincipalClient(t, srv)
+	ac.TokenCacheDir = cacheDir
+	if err := ac.Login(context.Background()); err != nil {
+		t.Fatalf("Login returned an error: %v", err)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(cacheDir, "*.json"))
+	if len(files) != 1 {
+		t.Fatalf("Got %d token cache files, expected 1", len(files))
+	}
+	info, err := os.Stat(files[0])
+	if err != nil {
+		t.Fatalf("Failed to stat the token cache: %v", err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("Token cache has permissions %v, expected 0600", info.Mode().Perm())
+	}
+
+	// a new client for the same principal, with its own copy of the credentials file, reuses the cached
+	// token without logging in
+	next := newServicePrincipalClient(t, srv)
+	next.TokenCacheDir = cacheDir
+	if err := next.Login(context.Background()); err != nil {
+		t.Fatalf("Login returned an error: %v", err)
+	}
+	if logins.Load() != 1 || next.Token != "token-1" {
+		t.Errorf("Got token %q after %d logins, expected the cached token-1 after 1 login", next.Token, logins.Load())
+	}
+
+	// a client for another tenant does not
+	other := newServicePrincipalClient(t, srv)
+	other.SecretFile = ac.SecretFile
+	other.TokenCacheDir = cacheDir
+	other.Tenant = "another_tenant"
+	_ = other.Login(context.Background())
+	if other.Token == "token-1" {
+		t.Errorf("Token cached for tenant %s was reused for another tenant", tenant)
+	}
+}
+
+func TestTokenCacheRefreshed(t *testing.T) {
+	// an expired cached access token is refreshed silently with the cached refresh token, which is rotated
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != tokenPath {
+			t.Errorf("Unexpected request to %s", r.URL.Path)
+			return
+		}
+		if err := r.ParseForm(); err != nil || r.PostForm.Get("refresh_token") != "refresh-1" {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"error": "invalid_grant"}`)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"access_token": "fresh", "refresh_token": "refresh-2", "expires_in": 3600}`)
+	}))
+	defer srv.Close()
+	cacheDir := t.TempDir()
+
+	// tokens handed over to the client are not cached, the ones obtained by refreshing them are
+	seed := &api.AppdClient{URL: srv.URL, Tenant: tenant, AuthMethod: oauth, APIClient: srv.Client(), TokenCacheDir: cacheDir}
+	seed.UseCachedTokens(newJWT(`{"exp": 946684800}`), "refresh-1")
+	if files, _ := filepath.Glob(filepath.Join(cacheDir, "*.json")); len(files) != 0 {
+		t.Errorf("Tokens handed over to the client were written to the token cache")
+	}
+	if err := seed.Login(context.Background()); err != nil {
+		t.Fatalf("Login returned an error: %v", err)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(cacheDir, "*.json"))
+	if len(files) != 1 {
+		t.Fatalf("Got %d token cache files, expected 1", len(files))
+	}
+	expired := `{"access_token": "expired", "refresh_token": "refresh-1", "expiry": "2000-01-01T00:00:00Z"}`
+	if err := os.WriteFile(files[0], []byte(expired), 0o600); err != nil {
+		t.Fatalf("Failed to write the token cache: %v", err)
+	}
+
+	ac := &api.AppdClient{URL: srv.URL, Tenant: tenant, AuthMethod: oauth, APIClient: srv.Client(), TokenCacheDir: cacheDir}
+	if err := ac.Login(context.Background()); err != nil {
+		t.Fatalf("Login returned an error: %v", err)
+	}
+	if ac.Token != "fresh" || ac.RefreshToken != "refresh-2" {
+		t.Errorf("Got tokens %q and %q, expected the refreshed ones", ac.Token, ac.RefreshToken)
+	}
+
+	data, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatalf("Failed to read the token cache: %v", err)
+	}
+	if !strings.Contains(string(data), `"refresh-2"`) {
+		t.Errorf("Token cache %s does not hold the rotated refresh token", data)
+	}
+}
@@ -0,0 +1,178 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+//
+// SPDX-License-Identifier: MPL-2.0
+
+package api
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+
+	"github.com/apex/log"
+	"golang.org/x/net/http/httpproxy"
+)
+
+// TransportOptions configures the connections of the HTTP client built by NewHTTPClient. Certificates and
+// keys can be given either as PEM files or as PEM strings; the zero value behaves like http.DefaultClient.
+type TransportOptions struct {
+	ProxyURL      string // proxy for all requests, e.g. http://proxy:3128; the HTTPS_PROXY env var and co. if empty
+	NoProxy       string // comma-separated hosts, domains and CIDRs reached directly, same syntax as NO_PROXY
+	ProxyUsername string // credentials for proxies requiring basic authentication
+	ProxyPassword string
+
+	CACertFile         string // additional CAs trusted on top of the system ones
+	CACertPEM          string
+	ClientCertFile     string // client certificate for mutual TLS, requires the matching key
+	ClientCertPEM      string
+	ClientKeyFile      string
+	ClientKeyPEM       string
+	MinTLSVersion      string // "1.2" (default) or "1.3"
+	InsecureSkipVerify bool   // do not verify the server certificate, for labs only
+}
+
+// tlsVersions maps the supported MinTLSVersion values to their crypto/tls constant
+var tlsVersions = map[string]uint16{
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// NewHTTPClient builds a dedicated HTTP client for AppdClient.APIClient with the given transport options
+func NewHTTPClient(opts *TransportOptions) (*http.Client, error) {
+	tlsConfig, err := opts.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	transport, ok := http.DefaultTransport.(*http.Transport)
+	if !ok {
+		return nil, errors.New("unexpected type of http.DefaultTransport")
+	}
+	transport = transport.Clone()
+	transport.TLSClientConfig = tlsConfig
+
+	proxy, err := opts.proxy()
+	if err != nil {
+		return nil, err
+	}
+	if proxy != nil {
+		transport.Proxy = proxy
+	}
+
+	return &http.Client{Transport: transport}, nil
+}
+
+func (opts *TransportOptions) tlsConfig() (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if opts.MinTLSVersion != "" {
+		version, ok := tlsVersions[opts.MinTLSVersion]
+		if !ok {
+			return nil, fmt.Errorf("unsupported minimum TLS version %q, expected 1.2 or 1.3", opts.MinTLSVersion)
+		}
+		tlsConfig.MinVersion = version
+	}
+
+	caPEM, err := pemContent("CA certificate", opts.CACertFile, opts.CACertPEM)
+	if err != nil {
+		return nil, err
+	}
+	if caPEM != nil {
+		pool, poolErr := x509.SystemCertPool()
+		if poolErr != nil {
+			log.Warnf("Failed to load the system CAs, trusting the configured CA only: %v", poolErr)
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(caPEM) {
+			return nil, errors.New("no valid PEM certificate found in the CA certificate")
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	certPEM, err := pemContent("client certificate", opts.ClientCertFile, opts.ClientCertPEM)
+	if err != nil {
+		return nil, err
+	}
+	keyPEM, err := pemContent("client key", opts.ClientKeyFile, opts.ClientKeyPEM)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case certPEM != nil && keyPEM != nil:
+		cert, certErr := tls.X509KeyPair(certPEM, keyPEM)
+		if certErr != nil {
+			return nil, fmt.Errorf("invalid client certificate or key: %w", certErr)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	case certPEM != nil || keyPEM != nil:
+		return nil, errors.New("mutual TLS requires both the client certificate and the client key")
+	}
+
+	if opts.InsecureSkipVerify {
+		log.Warnf("TLS certificate verification is disabled, connections are not secure")
+		tlsConfig.InsecureSkipVerify = true //nolint:gosec // explicitly requested, for labs only
+	}
+
+	return tlsConfig, nil
+}
+
+// proxy returns the proxy selection function for the configured proxy, nil to keep the default one
+// (from the environment)
+func (opts *TransportOptions) proxy() (func(*http.Request) (*url.URL, error), error) {
+	if opts.ProxyUsername != "" && opts.ProxyURL == "" {
+		return nil, errors.New("proxy credentials require a proxy URL")
+	}
+	if opts.ProxyURL == "" && opts.NoProxy == "" {
+		return nil, nil
+	}
+
+	// a proxy URL applies to this client only, otherwise NoProxy amends the environment settings
+	config := httpproxy.FromEnvironment()
+	if opts.ProxyURL != "" {
+		proxyURL, err := url.Parse(opts.ProxyURL)
+		if err != nil || proxyURL.Host == "" {
+			return nil, fmt.Errorf("invalid proxy URL %q, expected e.g. http://proxy.example.com:3128", opts.ProxyURL)
+		}
+		switch proxyURL.Scheme {
+		case "http", "https", "socks5":
+		default:
+			return nil, fmt.Errorf("unsupported proxy URL scheme %q, expected http, https or socks5", proxyURL.Scheme)
+		}
+		if opts.ProxyUsername != "" {
+			proxyURL.User = url.UserPassword(opts.ProxyUsername, opts.ProxyPassword)
+		}
+		config.HTTPProxy = proxyURL.String()
+		config.HTTPSProxy = proxyURL.String()
+	}
+	if opts.NoProxy != "" {
+		config.NoProxy = opts.NoProxy
+	}
+
+	proxyFunc := config.ProxyFunc()
+	return func(req *http.Request) (*url.URL, error) {
+		return proxyFunc(req.URL)
+	}, nil
+}
+
+// pemContent returns the PEM given as a string or read from file, nil if neither is set
+func pemContent(what, file, pem string) ([]byte, error) {
+	switch {
+	case file != "" && pem != "":
+		return nil, fmt.Errorf("the %s can be set either as a file or as PEM, not both", what)
+	case pem != "":
+		return []byte(pem), nil
+	case file != "":
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the %s file %q: %w", what, file, err)
+		}
+		return data, nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,209 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+//
+// SPDX-License-Identifier: MPL-2.0
+
+//go:build unit
+
+package api_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cisco-open/terraform-provider-observability/internal/api"
+)
+
+// newClientCertificate returns a self-signed client certificate and its key as PEM
+func newClientCertificate(t *testing.T) (certPEM, keyPEM []byte) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate the client key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "terraform"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create the client certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("Failed to encode the client key: %v", err)
+	}
+
+	certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM = pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	return certPEM, keyPEM
+}
+
+func serverCAPEM(srv *httptest.Server) string {
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw}))
+}
+
+func get(client *http.Client, url string) error {
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, url, http.NoBody)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+func TestNewHTTPClientCA(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	defer srv.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(caFile, []byte(serverCAPEM(srv)), 0o600); err != nil {
+		t.Fatalf("Failed to write the CA file: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		opts    api.TransportOptions
+		success bool
+	}{
+		{"system CAs only", api.TransportOptions{}, false},
+		{"CA PEM", api.TransportOptions{CACertPEM: serverCAPEM(srv)}, true},
+		{"CA file", api.TransportOptions{CACertFile: caFile}, true},
+		{"insecure", api.TransportOptions{InsecureSkipVerify: true}, true},
+	}
+
+	for _, test := range tests {
+		opts := test.opts
+		client, err := api.NewHTTPClient(&opts)
+		if err != nil {
+			t.Errorf("%s: NewHTTPClient returned an error: %v", test.name, err)
+			continue
+		}
+		err = get(client, srv.URL)
+		if test.success && err != nil {
+			t.Errorf("%s: request failed: %v", test.name, err)
+		} else if !test.success && err == nil {
+			t.Errorf("%s: request succeeded, expected a certificate error", test.name)
+		}
+	}
+}
+
+func TestNewHTTPClientMutualTLS(t *testing.T) {
+	certPEM, keyPEM := newClientCertificate(t)
+	clientCAs := x509.NewCertPool()
+	clientCAs.AppendCertsFromPEM(certPEM)
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	srv.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs, MinVersion: tls.VersionTLS12}
+	srv.StartTLS()
+	defer srv.Close()
+
+	client, err := api.NewHTTPClient(&api.TransportOptions{
+		CACertPEM:     serverCAPEM(srv),
+		ClientCertPEM: string(certPEM),
+		ClientKeyPEM:  string(keyPEM),
+		MinTLSVersion: "1.3",
+	})
+	if err != nil {
+		t.Fatalf("NewHTTPClient returned an error: %v", err)
+	}
+	if err = get(client, srv.URL); err != nil {
+		t.Errorf("Request with the client certificate failed: %v", err)
+	}
+
+	client, err = api.NewHTTPClient(&api.TransportOptions{CACertPEM: serverCAPEM(srv)})
+	if err != nil {
+		t.Fatalf("NewHTTPClient returned an error: %v", err)
+	}
+	if err = get(client, srv.URL); err == nil {
+		t.Errorf("Request without the client certificate succeeded")
+	}
+}
+
+func TestNewHTTPClientInvalidOptions(t *testing.T) {
+	certPEM, keyPEM := newClientCertificate(t)
+
+	tests := []struct {
+		name string
+		opts api.TransportOptions
+	}{
+		{"certificate without key", api.TransportOptions{ClientCertPEM: string(certPEM)}},
+		{"mismatched key", api.TransportOptions{ClientCertPEM: string(certPEM), ClientKeyPEM: string(certPEM)}},
+		{"file and PEM", api.TransportOptions{ClientKeyFile: "key.pem", ClientKeyPEM: string(keyPEM)}},
+		{"missing file", api.TransportOptions{CACertFile: "/does/not/exist.pem"}},
+		{"invalid CA", api.TransportOptions{CACertPEM: "not a certificate"}},
+		{"unsupported TLS version", api.TransportOptions{MinTLSVersion: "1.0"}},
+		{"invalid proxy URL", api.TransportOptions{ProxyURL: "proxy:3128"}},
+		{"proxy credentials without proxy", api.TransportOptions{ProxyUsername: "user"}},
+	}
+
+	for _, test := range tests {
+		opts := test.opts
+		if _, err := api.NewHTTPClient(&opts); err == nil {
+			t.Errorf("%s: NewHTTPClient returned no error", test.name)
+		}
+	}
+}
+
+func TestNewHTTPClientProxy(t *testing.T) {
+	var proxied atomic.Int32
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied.Add(1)
+		if r.URL.Host != "tenant.example.invalid" {
+			t.Errorf("Unexpected proxied request for %s", r.URL)
+		}
+		credentials := base64.StdEncoding.EncodeToString([]byte("proxy-user:proxy-password"))
+		if got := r.Header.Get("Proxy-Authorization"); got != "Basic "+credentials {
+			t.Errorf("Unexpected proxy credentials %q", got)
+		}
+	}))
+	defer proxy.Close()
+
+	client, err := api.NewHTTPClient(&api.TransportOptions{
+		ProxyURL:      proxy.URL,
+		ProxyUsername: "proxy-user",
+		ProxyPassword: "proxy-password",
+	})
+	if err != nil {
+		t.Fatalf("NewHTTPClient returned an error: %v", err)
+	}
+	if err = get(client, "http://tenant.example.invalid/knowledge-store/v1/types"); err != nil {
+		t.Errorf("Request through the proxy failed: %v", err)
+	}
+
+	// hosts matching no_proxy are reached directly, which fails for an invalid domain
+	client, err = api.NewHTTPClient(&api.TransportOptions{ProxyURL: proxy.URL, NoProxy: "example.invalid"})
+	if err != nil {
+		t.Fatalf("NewHTTPClient returned an error: %v", err)
+	}
+	if err = get(client, "http://tenant.example.invalid/knowledge-store/v1/types"); err == nil {
+		t.Errorf("Request to a no_proxy host went through the proxy")
+	}
+
+	if got := proxied.Load(); got != 1 {
+		t.Errorf("The proxy received %d requests, expected 1", got)
+	}
+}
@@ -7,40 +7,128 @@
 package api
 
 import (
-	"fmt"
-	"io"
+	"context"
+	"encoding/json"
 	"net/http"
+	"strings"
 )
 
 // GetType is a method used to GET the type based on the fullyQualifiedTypeName
-func (ac *AppdClient) GetType(fullyQualifiedTypeName string) ([]byte, error) {
+// Failed requests are reported as *APIError, use IsNotFound to detect a missing type
+func (ac *AppdClient) GetType(ctx context.Context, fullyQualifiedTypeName string) ([]byte, error) {
 	url := ac.URL + typeAPIPath + fullyQualifiedTypeName
 
-	//nolint:noctx // To be removed in the future
-	req, err := http.NewRequest(http.MethodGet, url, http.NoBody)
+	req, err := ac.newAPIRequest(ctx, http.MethodGet, url, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create a request for %q: %w", url, err)
+		return nil, err
 	}
 
-	// Add headers
-	req.Header.Add("Content-Type", jsonContentType)
-	req.Header.Add("Accept", jsonContentType)
-	req.Header.Add("Authorization", "Bearer "+ac.Token)
+	// Do request
+	_, respBytes, err := ac.doRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	return respBytes, nil
+}
+
+// CreateType is a method used to POST a knowledge store type definition set in the body,
+// the definition carries the type name and the solution it belongs to
+// layerID and layerType (SOLUTION/TENANT/...) select the layer the type is created in
+// Failed requests are reported as *APIError, use IsConflict to detect an already existing type
+func (ac *AppdClient) CreateType(ctx context.Context, layerID, layerType string, body []byte) error {
+	url := ac.URL + strings.TrimSuffix(typeAPIPath, "/")
+
+	req, err := ac.newAPIRequest(ctx, http.MethodPost, url, body)
+	if err != nil {
+		return err
+	}
+	setLayerHeaders(req, layerID, layerType)
 
 	// Do request
-	resp, err := ac.APIClient.Do(req)
+	_, _, err = ac.doRequest(req)
+	return err
+}
+
+// UpdateType is a method used to PUT the knowledge store type definition
+// based on the fullyQualifiedTypeName with the definition set in the body
+// layerID and layerType (SOLUTION/TENANT/...) select the layer the type resides in
+// Failed requests are reported as *APIError, use IsNotFound to detect a missing type
+func (ac *AppdClient) UpdateType(ctx context.Context, fullyQualifiedTypeName, layerID, layerType string, body []byte,
+	opts ...RequestOption) error {
+	url := ac.URL + typeAPIPath + fullyQualifiedTypeName
+
+	req, err := ac.newAPIRequest(ctx, http.MethodPut, url, body)
 	if err != nil {
-		return nil, fmt.Errorf("%v request to %q failed: %w", http.MethodGet, req.URL.String(), err)
+		return err
 	}
+	setLayerHeaders(req, layerID, layerType)
+	applyRequestOptions(req, opts)
 
-	// Unmarshal the contents
-	var respBytes []byte
-	defer resp.Body.Close()
+	// Do request
+	_, _, err = ac.doRequest(req)
+	return err
+}
+
+// DeleteType is a method used to DELETE the knowledge store type definition
+// based on the fullyQualifiedTypeName
+// layerID and layerType (SOLUTION/TENANT/...) select the layer the type resides in
+// Failed requests are reported as *APIError, use IsNotFound to detect a missing type
+func (ac *AppdClient) DeleteType(ctx context.Context, fullyQualifiedTypeName, layerID, layerType string,
+	opts ...RequestOption) error {
+	url := ac.URL + typeAPIPath + fullyQualifiedTypeName
 
-	respBytes, err = io.ReadAll(resp.Body)
+	req, err := ac.newAPIRequest(ctx, http.MethodDelete, url, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed reading response to %v to %q (status %v): %w", http.MethodGet, req.URL.String(), resp.StatusCode, err)
+		return err
 	}
+	setLayerHeaders(req, layerID, layerType)
+	applyRequestOptions(req, opts)
 
-	return respBytes, nil
+	// Do request
+	_, _, err = ac.doRequest(req)
+	return err
+}
+
+// TypeIterator walks all type definitions visible in a layer, fetching further pages from the knowledge
+// store as needed. It is used the same way as ObjectIterator.
+type TypeIterator struct {
+	p *pager[json.RawMessage]
+}
+
+// ListTypes returns an iterator over the type definitions visible in the given layer.
+// No request is made until the first call to Next.
+func (ac *AppdClient) ListTypes(ctx context.Context, layerID, layerType string) *TypeIterator {
+	url := ac.URL + strings.TrimSuffix(typeAPIPath, "/")
+	return &TypeIterator{p: newPager[json.RawMessage](ctx, ac, url, layerID, layerType)}
+}
+
+// Next advances the iterator to the next type definition, returning false when there are no more
+// types or a request failed (see Err)
+func (it *TypeIterator) Next() bool {
+	return it.p.next()
+}
+
+// Type returns the JSON definition of the current type; only valid after Next returned true
+func (it *TypeIterator) Type() json.RawMessage {
+	return it.p.current
+}
+
+// Total returns the total number of types reported by the knowledge store with the last fetched page
+func (it *TypeIterator) Total() int {
+	return it.p.total
+}
+
+// Err returns the error that stopped the iteration, if any
+func (it *TypeIterator) Err() error {
+	return it.p.err
+}
+
+// All drains the iterator and returns all the remaining type definitions
+func (it *TypeIterator) All() ([]json.RawMessage, error) {
+	var types []json.RawMessage
+	for it.Next() {
+		types = append(types, it.Type())
+	}
+	return types, it.Err()
 }
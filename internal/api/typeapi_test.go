@@ -9,9 +9,14 @@
 package api_test
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/cisco-open/terraform-provider-observability/internal/api"
 )
@@ -36,7 +41,7 @@ func TestGetType(t *testing.T) {
 	}
 
 	// Call the GetType method
-	response, err := ac.GetType(testType)
+	response, err := ac.GetType(context.Background(), testType)
 
 	// Check for any errors
 	if err != nil {
@@ -48,3 +53,125 @@ func TestGetType(t *testing.T) {
 		t.Errorf("GetType returned incorrect response: got %s, want %s", response, expectedResponse)
 	}
 }
+
+func TestGetTypeCancelled(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// block until the client gives up on the request
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	ac := &api.AppdClient{
+		URL:       srv.URL,
+		Tenant:    tenant,
+		APIClient: srv.Client(),
+		Token:     token,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	// Call the GetType method, it must return once the deadline is exceeded
+	_, err := ac.GetType(ctx, testType)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("GetType returned %v, expected a deadline exceeded error", err)
+	}
+}
+
+func TestTypeCRUD(t *testing.T) {
+	const definition = `{"name": "sample_type", "solution": "sample", "jsonSchema": {}}`
+
+	type call struct{ method, path, layerType, layerID, body string }
+	var calls []call
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		calls = append(calls, call{r.Method, r.URL.Path, r.Header.Get("Layer-Type"), r.Header.Get("Layer-Id"), string(body)})
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	ac := &api.AppdClient{
+		URL:       srv.URL,
+		Tenant:    tenant,
+		APIClient: srv.Client(),
+		Token:     token,
+	}
+	ctx := context.Background()
+
+	if err := ac.CreateType(ctx, sampleLayerID, "SOLUTION", []byte(definition)); err != nil {
+		t.Errorf("CreateType returned an error: %v", err)
+	}
+	if err := ac.UpdateType(ctx, testType, sampleLayerID, "SOLUTION", []byte(definition)); err != nil {
+		t.Errorf("UpdateType returned an error: %v", err)
+	}
+	if err := ac.DeleteType(ctx, testType, sampleLayerID, "SOLUTION"); err != nil {
+		t.Errorf("DeleteType returned an error: %v", err)
+	}
+
+	expected := []call{
+		{http.MethodPost, "/knowledge-store/v1/types", "SOLUTION", sampleLayerID, definition},
+		{http.MethodPut, "/knowledge-store/v1/types/" + testType, "SOLUTION", sampleLayerID, definition},
+		{http.MethodDelete, "/knowledge-store/v1/types/" + testType, "SOLUTION", sampleLayerID, ""},
+	}
+	if len(calls) != len(expected) {
+		t.Fatalf("Got %d requests, expected %d", len(calls), len(expected))
+	}
+	for i := range expected {
+		if calls[i] != expected[i] {
+			t.Errorf("Request %d was %+v, expected %+v", i, calls[i], expected[i])
+		}
+	}
+}
+
+func TestDeleteTypeNotFound(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	ac := &api.AppdClient{
+		URL:       srv.URL,
+		Tenant:    tenant,
+		APIClient: srv.Client(),
+		Token:     token,
+	}
+
+	err := ac.DeleteType(context.Background(), testType, sampleLayerID, "SOLUTION")
+	if !api.IsNotFound(err) {
+		t.Errorf("DeleteType returned %v, expected a not found error", err)
+	}
+}
+
+func TestListTypes(t *testing.T) {
+	const typesPath = "/knowledge-store/v1/types"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != typesPath {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("cursor") == "" {
+			fmt.Fprintf(w, `{"items": [{"name": "a"}, {"name": "b"}], "total": 3, "_links": {"next": {"href": "%s?cursor=2"}}}`, typesPath)
+			return
+		}
+		_, _ = w.Write([]byte(`{"items": [{"name": "c"}], "total": 3, "_links": {}}`))
+	}))
+	defer srv.Close()
+
+	ac := &api.AppdClient{
+		URL:       srv.URL,
+		Tenant:    tenant,
+		APIClient: srv.Client(),
+		Token:     token,
+	}
+
+	types, err := ac.ListTypes(context.Background(), sampleLayerID, "SOLUTION").All()
+	if err != nil {
+		t.Fatalf("ListTypes returned an error: %v", err)
+	}
+	if len(types) != 3 {
+		t.Fatalf("Got %d types, expected 3", len(types))
+	}
+	if string(types[2]) != `{"name": "c"}` {
+		t.Errorf("Got type %s, expected the definition from the second page", types[2])
+	}
+}
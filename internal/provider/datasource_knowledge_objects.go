@@ -0,0 +1,214 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+//
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cisco-open/terraform-provider-observability/pkg/client"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &KnowledgeObjectsDataSource{}
+
+func NewKnowledgeObjectsDataSource() datasource.DataSource {
+	return &KnowledgeObjectsDataSource{}
+}
+
+// KnowledgeObjectsDataSource defines the data source implementation.
+type KnowledgeObjectsDataSource struct {
+	client *client.Client
+}
+
+// KnowledgeObjectsDataSourceModel describes the data source data model.
+type KnowledgeObjectsDataSourceModel struct {
+	TypeName   types.String            `tfsdk:"type_name"`
+	LayerID    types.String            `tfsdk:"layer_id"`
+	LayerType  types.String            `tfsdk:"layer_type"`
+	Filters    []types.String          `tfsdk:"filters"`
+	SortBy     types.String            `tfsdk:"sort_by"`
+	SortOrder  types.String            `tfsdk:"sort_order"`
+	MaxResults types.Int64             `tfsdk:"max_results"`
+	Fields     []types.String          `tfsdk:"fields"`
+	Objects    []KnowledgeObjectsModel `tfsdk:"objects"`
+	ID         types.String            `tfsdk:"id"`
+}
+
+// KnowledgeObjectsModel describes a single object returned by the data source.
+type KnowledgeObjectsModel struct {
+	ObjectID  types.String `tfsdk:"object_id"`
+	LayerID   types.String `tfsdk:"layer_id"`
+	LayerType types.String `tfsdk:"layer_type"`
+	Data      types.String `tfsdk:"data"`
+}
+
+func (d *KnowledgeObjectsDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_objects"
+}
+
+// Schema defines the schema for the data source.
+func (d *KnowledgeObjectsDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Objects data source",
+		Attributes: map[string]schema.Attribute{
+			"type_name": schema.StringAttribute{
+				MarkdownDescription: "Specifies the fully qualified type name of the objects to list",
+				Required:            true,
+			},
+			"layer_id": schema.StringAttribute{
+				MarkdownDescription: "Specifies the layer ID where the objects reside",
+				Required:            true,
+			},
+			"layer_type": schema.StringAttribute{
+				MarkdownDescription: "Specifies the layer type where the objects reside",
+				Required:            true,
+			},
+			"filters": schema.ListAttribute{
+				MarkdownDescription: "Knowledge store filter expressions the objects must all match, e.g. `data.region eq \"us-east-2\"`",
+				ElementType:         types.StringType,
+				Optional:            true,
+			},
+			"sort_by": schema.StringAttribute{
+				MarkdownDescription: "Field of the object data used to sort the objects, e.g. `connectionName`",
+				Optional:            true,
+			},
+			"sort_order": schema.StringAttribute{
+				MarkdownDescription: "Sort order used with sort_by. Possible values(asc, desc)",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(string(client.SortAscending), string(client.SortDescending)),
+				},
+			},
+			"max_results": schema.Int64Attribute{
+				MarkdownDescription: "Maximum number of objects to return",
+				Optional:            true,
+				Validators: []validator.Int64{
+					int64validator.AtLeast(1),
+				},
+			},
+			"fields": schema.ListAttribute{
+				MarkdownDescription: "Fields of the object data to return, all fields are returned when omitted",
+				ElementType:         types.StringType,
+				Optional:            true,
+			},
+			"objects": schema.ListNestedAttribute{
+				MarkdownDescription: "Objects matching the query",
+				Computed:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"object_id": schema.StringAttribute{
+							MarkdownDescription: "ID of the object",
+							Computed:            true,
+						},
+						"layer_id": schema.StringAttribute{
+							MarkdownDescription: "Layer ID where the object resides",
+							Computed:            true,
+						},
+						"layer_type": schema.StringAttribute{
+							MarkdownDescription: "Layer type where the object resides",
+							Computed:            true,
+						},
+						"data": schema.StringAttribute{
+							MarkdownDescription: "JSON payload of the object",
+							Computed:            true,
+						},
+					},
+				},
+			},
+			"id": schema.StringAttribute{
+				MarkdownDescription: "Used to provide compatibility for testing framework",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (d *KnowledgeObjectsDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	observabilityClient, ok := req.ProviderData.(*client.Client)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *client.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	d.client = observabilityClient
+}
+
+//nolint:gocritic // Terraform framework requires the method signature to be as is
+func (d *KnowledgeObjectsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data KnowledgeObjectsDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// build the server-side query
+	query := client.NewObjectQuery()
+	for _, filter := range data.Filters {
+		query.Filter(filter.ValueString())
+	}
+	if !data.SortBy.IsNull() {
+		order := client.SortAscending
+		if !data.SortOrder.IsNull() {
+			order = client.SortOrder(data.SortOrder.ValueString())
+		}
+		query.SortBy(data.SortBy.ValueString(), order)
+	}
+	if !data.MaxResults.IsNull() {
+		query.Limit(int(data.MaxResults.ValueInt64()))
+	}
+	for _, field := range data.Fields {
+		query.Fields(field.ValueString())
+	}
+
+	// issue the API calls
+	typeName := data.TypeName.ValueString()
+	it := d.client.ListObjects(ctx, typeName, data.LayerID.ValueString(), data.LayerType.ValueString(), query)
+
+	data.Objects = []KnowledgeObjectsModel{}
+	for it.Next() {
+		obj := it.Object()
+		data.Objects = append(data.Objects, KnowledgeObjectsModel{
+			ObjectID:  types.StringValue(obj.ID),
+			LayerID:   types.StringValue(obj.LayerID),
+			LayerType: types.StringValue(obj.LayerType),
+			Data:      types.StringValue(string(obj.Data)),
+		})
+	}
+	if err := it.Err(); err != nil {
+		addAPIErrorDiagnostic(&resp.Diagnostics, fmt.Sprintf("Unable to List objects of type %s", typeName), err)
+		return
+	}
+
+	tflog.Debug(ctx, fmt.Sprintf("listed %d objects of type %s", len(data.Objects), typeName))
+
+	// set the placeholder value for testing purposses
+	data.ID = types.StringValue("placeholder")
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
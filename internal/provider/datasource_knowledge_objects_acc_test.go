@@ -0,0 +1,43 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+//
+// SPDX-License-Identifier: MPL-2.0
+
+//go:build acceptance
+
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccKnowledgeObjectsDataSource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Read testing
+			{
+				Config: providerConfig + `data "observability_objects" "test" {
+					type_name   = "anzen:cloudConnection"
+					layer_type  = "TENANT"
+					layer_id    = "0eb4e853-34fb-4f77-b3fc-b9cd3b462366"
+					filters     = ["data.cloudType eq \"AWS\""]
+					sort_by     = "connectionName"
+					max_results = 1
+					fields      = ["connectionName", "cloudType"]
+				}`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.observability_objects.test", "type_name", "anzen:cloudConnection"),
+					resource.TestCheckResourceAttr("data.observability_objects.test", "objects.#", "1"),
+					resource.TestCheckResourceAttr("data.observability_objects.test", "objects.0.layer_type", "TENANT"),
+
+					// Verify placeholder id attribute
+					resource.TestCheckResourceAttr("data.observability_objects.test", "id", "placeholder"),
+				),
+			},
+		},
+	})
+}
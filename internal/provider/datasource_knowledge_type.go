@@ -7,7 +7,7 @@ import (
 	"context"
 	"fmt"
 
-	"github.com/cisco-open/terraform-provider-observability/internal/api"
+	"github.com/cisco-open/terraform-provider-observability/pkg/client"
 
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
@@ -24,7 +24,7 @@ func NewKnowledgeTypeDataSource() datasource.DataSource {
 
 // KnowledgeTypeDataSource defines the data source implementation.
 type KnowledgeTypeDataSource struct {
-	client *api.AppdClient
+	client *client.Client
 }
 
 // KnowledgeTypeDataSourceModel describes the data source data model.
@@ -67,16 +67,16 @@ func (d *KnowledgeTypeDataSource) Configure(_ context.Context, req datasource.Co
 		return
 	}
 
-	client, ok := req.ProviderData.(*api.AppdClient)
+	observabilityClient, ok := req.ProviderData.(*client.Client)
 	if !ok {
 		resp.Diagnostics.AddError(
 			"Unexpected Data Source Configure Type",
-			fmt.Sprintf("Expected *api.AppdClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+			fmt.Sprintf("Expected *client.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
 		)
 		return
 	}
 
-	d.client = client
+	d.client = observabilityClient
 }
 
 //nolint:gocritic // Terraform framework requires the method signature to be as is
@@ -92,12 +92,9 @@ func (d *KnowledgeTypeDataSource) Read(ctx context.Context, req datasource.ReadR
 
 	// issue the API call
 	typeName := data.Typename
-	result, err := d.client.GetType(typeName.ValueString())
+	result, err := d.client.GetType(ctx, typeName.ValueString())
 	if err != nil {
-		resp.Diagnostics.AddError(
-			fmt.Sprintf("Unable to Read type %s", typeName),
-			err.Error(),
-		)
+		addAPIErrorDiagnostic(&resp.Diagnostics, fmt.Sprintf("Unable to Read type %s", typeName), err)
 		return
 	}
 
@@ -0,0 +1,76 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+//
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/cisco-open/terraform-provider-observability/pkg/client"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+)
+
+// addAPIErrorDiagnostic appends an error diagnostic for a failed API call, detailing
+// the platform error (status, error code, request ID) when one is available
+func addAPIErrorDiagnostic(diags *diag.Diagnostics, summary string, err error) {
+	var apiErr *client.APIError
+	if !errors.As(err, &apiErr) {
+		diags.AddError(summary, err.Error())
+		return
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "The observability platform rejected the %v request to %s with status %d (%s).\n",
+		apiErr.Method, apiErr.URL, apiErr.StatusCode, http.StatusText(apiErr.StatusCode))
+	if apiErr.Message != "" {
+		fmt.Fprintf(&sb, "\nMessage: %s", apiErr.Message)
+	}
+	if apiErr.Code != "" {
+		fmt.Fprintf(&sb, "\nError code: %s", apiErr.Code)
+	}
+	if apiErr.RequestID != "" {
+		fmt.Fprintf(&sb, "\nRequest ID: %s", apiErr.RequestID)
+	}
+	if hint := apiErrorHint(apiErr.StatusCode); hint != "" {
+		fmt.Fprintf(&sb, "\n\n%s", hint)
+	}
+
+	diags.AddError(summary, sb.String())
+}
+
+// addObjectChangedDiagnostic appends an error diagnostic for a conditional update or delete that was
+// rejected because the object was modified since Terraform last read it
+func addObjectChangedDiagnostic(diags *diag.Diagnostics, typeName, objID string) {
+	diags.AddError(
+		fmt.Sprintf("Object of type %s with id %s changed outside Terraform", typeName, objID),
+		"The object was modified by someone else since Terraform last read it, so the change was not applied "+
+			"to avoid overwriting theirs.\n\nRun `terraform plan` (or `terraform apply -refresh-only`) to review "+
+			"the remote changes, then apply again.",
+	)
+}
+
+func apiErrorHint(status int) string {
+	switch status {
+	case http.StatusUnauthorized:
+		return "The access token is missing, invalid or expired; please verify the provider authentication settings."
+	case http.StatusForbidden:
+		return "The authenticated principal is not permitted to perform this operation on the selected layer."
+	case http.StatusNotFound:
+		return "The requested type or object does not exist in the selected layer."
+	case http.StatusConflict:
+		return "The object conflicts with an existing one; consider importing the existing object instead."
+	case http.StatusPreconditionFailed:
+		return "The object was modified since it was last read; refresh the state and try again."
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return "The platform is temporarily unavailable or throttling requests; please retry later."
+	default:
+		return ""
+	}
+}
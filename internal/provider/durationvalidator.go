@@ -0,0 +1,45 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+//
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/helpers/validatordiag"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+)
+
+// IsValidDuration validates that a string is a positive Go duration, e.g. "30s" or "1m30s"
+type IsValidDuration struct{}
+
+func (v IsValidDuration) Description(_ context.Context) string {
+	return `value must be a positive duration such as "30s", "1m30s" or "500ms"`
+}
+
+func (v IsValidDuration) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+//nolint:gocritic // Terraform framework requires the method signature to be as is
+func (v IsValidDuration) ValidateString(ctx context.Context, request validator.StringRequest, response *validator.StringResponse) {
+	if request.ConfigValue.IsNull() || request.ConfigValue.IsUnknown() {
+		return
+	}
+
+	value := request.ConfigValue
+
+	if d, err := time.ParseDuration(value.ValueString()); err == nil && d > 0 {
+		return
+	}
+
+	response.Diagnostics.Append(validatordiag.InvalidAttributeValueMatchDiagnostic(
+		request.Path,
+		v.Description(ctx),
+		value.String(),
+	))
+}
@@ -0,0 +1,52 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+//
+// SPDX-License-Identifier: MPL-2.0
+
+//go:build unit
+
+package provider_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cisco-open/terraform-provider-observability/internal/provider"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// unit test for IsValidDuration
+func TestIsValidDuration(t *testing.T) {
+	myValidator := provider.IsValidDuration{}
+
+	tests := []struct {
+		input         string
+		expectedValid bool
+	}{
+		{"30s", true},     // seconds
+		{"1m30s", true},   // composite duration
+		{"500ms", true},   // milliseconds
+		{"0s", false},     // zero is not a usable duration
+		{"-5s", false},    // negative duration
+		{"30", false},     // missing unit
+		{"thirty", false}, // not a duration
+		{"", false},       // empty string
+	}
+
+	for _, test := range tests {
+		req := validator.StringRequest{
+			ConfigValue: types.StringValue(test.input),
+		}
+		resp := &validator.StringResponse{}
+
+		myValidator.ValidateString(context.Background(), req, resp)
+
+		if test.expectedValid && resp.Diagnostics.HasError() {
+			t.Errorf("Expected '%s' to be valid, but got errors: %v", test.input, resp.Diagnostics)
+		} else if !test.expectedValid && !resp.Diagnostics.HasError() {
+			t.Errorf("Expected '%s' to be invalid, but got no errors", test.input)
+		}
+	}
+}
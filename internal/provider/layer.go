@@ -0,0 +1,61 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+//
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cisco-open/terraform-provider-observability/pkg/client"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// planDefaultLayer sets the layer_type and layer_id left unset in the configuration of an object resource
+// to the provider defaults, so that the plan shows the layer the object is managed in. Since objects cannot
+// move to another layer, the object is replaced if the defaults changed since it was created.
+func planDefaultLayer(ctx context.Context, observabilityClient *client.Client,
+	req *resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// nothing to plan on destroy, nor before the provider is configured
+	if req.Plan.Raw.IsNull() || observabilityClient == nil {
+		return
+	}
+
+	layerType, layerID := observabilityClient.DefaultLayer()
+	planDefaultLayerAttribute(ctx, req, resp, "layer_type", layerType)
+	planDefaultLayerAttribute(ctx, req, resp, "layer_id", layerID)
+}
+
+func planDefaultLayerAttribute(ctx context.Context, req *resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse,
+	attr, defaultValue string) {
+	var configured types.String
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root(attr), &configured)...)
+	if resp.Diagnostics.HasError() || !configured.IsNull() {
+		return
+	}
+
+	if defaultValue == "" {
+		resp.Diagnostics.AddAttributeError(
+			path.Root(attr),
+			fmt.Sprintf("Missing %s", attr),
+			fmt.Sprintf("Set %s on the resource, or default_%s on the provider to use it for all the resources.", attr, attr),
+		)
+		return
+	}
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root(attr), defaultValue)...)
+
+	if req.State.Raw.IsNull() {
+		return
+	}
+	var prior types.String
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root(attr), &prior)...)
+	if !prior.IsNull() && prior.ValueString() != defaultValue {
+		resp.RequiresReplace = append(resp.RequiresReplace, path.Root(attr))
+	}
+}
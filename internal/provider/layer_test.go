@@ -0,0 +1,170 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+//
+// SPDX-License-Identifier: MPL-2.0
+
+//go:build unit
+
+package provider_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cisco-open/terraform-provider-observability/internal/provider"
+	"github.com/cisco-open/terraform-provider-observability/pkg/client"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+const layerTestTenant = "0eb4e853-34fb-4f77-b3fc-b9cd3b462366"
+
+// planObject runs the plan modifier of the object resource on an object configured with the given attributes,
+// a new one if there are no prior state attributes
+func planObject(t *testing.T, opts []client.Option, attrs, priorAttrs map[string]tftypes.Value) *resource.ModifyPlanResponse {
+	t.Helper()
+	ctx := context.Background()
+
+	r, ok := provider.NewKnowledgeObjectResource().(interface {
+		resource.ResourceWithConfigure
+		resource.ResourceWithModifyPlan
+	})
+	if !ok {
+		t.Fatalf("The object resource does not modify its plan")
+	}
+	c, err := client.New("https://mytenant.observe.appdynamics.com", layerTestTenant, append(opts, client.WithOAuth())...)
+	if err != nil {
+		t.Fatalf("New returned an error: %v", err)
+	}
+	var configureResp resource.ConfigureResponse
+	r.Configure(ctx, resource.ConfigureRequest{ProviderData: c}, &configureResp)
+
+	var schemaResp resource.SchemaResponse
+	r.Schema(ctx, resource.SchemaRequest{}, &schemaResp)
+	objectType, ok := schemaResp.Schema.Type().TerraformType(ctx).(tftypes.Object)
+	if !ok {
+		t.Fatalf("Unexpected resource schema type")
+	}
+
+	// like the framework, plan the computed attributes left unset in the configuration as unknown
+	configValues := make(map[string]tftypes.Value, len(objectType.AttributeTypes))
+	planValues := make(map[string]tftypes.Value, len(objectType.AttributeTypes))
+	priorValues := make(map[string]tftypes.Value, len(objectType.AttributeTypes))
+	for name, attrType := range objectType.AttributeTypes {
+		configValues[name] = tftypes.NewValue(attrType, nil)
+		planValues[name] = tftypes.NewValue(attrType, tftypes.UnknownValue)
+		priorValues[name] = tftypes.NewValue(attrType, nil)
+	}
+	for name, value := range attrs {
+		configValues[name] = value
+		planValues[name] = value
+	}
+	state := tfsdk.State{Schema: schemaResp.Schema, Raw: tftypes.NewValue(objectType, nil)}
+	if priorAttrs != nil {
+		for name, value := range priorAttrs {
+			priorValues[name] = value
+		}
+		state.Raw = tftypes.NewValue(objectType, priorValues)
+	}
+
+	plan := tfsdk.Plan{Schema: schemaResp.Schema, Raw: tftypes.NewValue(objectType, planValues)}
+	req := resource.ModifyPlanRequest{
+		Config: tfsdk.Config{Schema: schemaResp.Schema, Raw: tftypes.NewValue(objectType, configValues)},
+		Plan:   plan,
+		State:  state,
+	}
+	resp := &resource.ModifyPlanResponse{Plan: plan}
+	r.ModifyPlan(ctx, req, resp)
+	return resp
+}
+
+func TestObjectDefaultLayer(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      []client.Option
+		attrs     map[string]tftypes.Value
+		prior     map[string]tftypes.Value
+		layerType string
+		layerID   string
+		replace   bool
+		errorPath string
+	}{
+		{
+			name:      "provider defaults",
+			opts:      []client.Option{client.WithDefaultLayer("SOLUTION", "sample-solution")},
+			layerType: "SOLUTION",
+			layerID:   "sample-solution",
+		},
+		{
+			name:      "tenant by default",
+			opts:      []client.Option{client.WithDefaultLayer("TENANT", "")},
+			layerType: "TENANT",
+			layerID:   layerTestTenant,
+		},
+		{
+			name:      "configured layer",
+			opts:      []client.Option{client.WithDefaultLayer("SOLUTION", "sample-solution")},
+			attrs:     map[string]tftypes.Value{"layer_type": str("TENANT"), "layer_id": str("sample-tenant")},
+			layerType: "TENANT",
+			layerID:   "sample-tenant",
+		},
+		{
+			name:      "unchanged provider defaults",
+			opts:      []client.Option{client.WithDefaultLayer("SOLUTION", "sample-solution")},
+			prior:     map[string]tftypes.Value{"layer_type": str("SOLUTION"), "layer_id": str("sample-solution")},
+			layerType: "SOLUTION",
+			layerID:   "sample-solution",
+		},
+		{
+			name:      "changed provider defaults",
+			opts:      []client.Option{client.WithDefaultLayer("SOLUTION", "sample-solution")},
+			prior:     map[string]tftypes.Value{"layer_type": str("SOLUTION"), "layer_id": str("another-solution")},
+			layerType: "SOLUTION",
+			layerID:   "sample-solution",
+			replace:   true,
+		},
+		{
+			name:      "no default layer type",
+			errorPath: "layer_type",
+		},
+	}
+
+	for _, test := range tests {
+		attrs := map[string]tftypes.Value{"type_name": str("fmm:namespace")}
+		for name, value := range test.attrs {
+			attrs[name] = value
+		}
+		resp := planObject(t, test.opts, attrs, test.prior)
+
+		if test.errorPath != "" {
+			errs := resp.Diagnostics.Errors()
+			if len(errs) == 0 {
+				t.Errorf("%s: got no error, expected one on %s", test.name, test.errorPath)
+				continue
+			}
+			if d, ok := errs[0].(diag.DiagnosticWithPath); !ok || !d.Path().Equal(path.Root(test.errorPath)) {
+				t.Errorf("%s: got diagnostics %v, expected an error on %s", test.name, resp.Diagnostics, test.errorPath)
+			}
+			continue
+		}
+		if resp.Diagnostics.HasError() {
+			t.Errorf("%s: unexpected diagnostics %v", test.name, resp.Diagnostics)
+			continue
+		}
+
+		var layerType, layerID types.String
+		resp.Diagnostics.Append(resp.Plan.GetAttribute(context.Background(), path.Root("layer_type"), &layerType)...)
+		resp.Diagnostics.Append(resp.Plan.GetAttribute(context.Background(), path.Root("layer_id"), &layerID)...)
+		if layerType.ValueString() != test.layerType || layerID.ValueString() != test.layerID {
+			t.Errorf("%s: planned layer %s %s, expected %s %s", test.name, layerType, layerID, test.layerType, test.layerID)
+		}
+		if replace := len(resp.RequiresReplace) > 0; replace != test.replace {
+			t.Errorf("%s: got replacement %v, expected %v", test.name, resp.RequiresReplace, test.replace)
+		}
+	}
+}
@@ -6,17 +6,24 @@ package provider
 import (
 	"context"
 	"fmt"
-	"net/http"
+	"net"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
-	"github.com/cisco-open/terraform-provider-observability/internal/api"
+	"github.com/cisco-open/terraform-provider-observability/pkg/client"
 
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/function"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
@@ -32,14 +39,49 @@ type COPProvider struct {
 
 // COPProviderModel describes the provider data model.
 type COPProviderModel struct {
-	Username    types.String `tfsdk:"username"`
-	Password    types.String `tfsdk:"password"`
-	URL         types.String `tfsdk:"url"`
-	AuthMethod  types.String `tfsdk:"auth_method"`
-	Tenant      types.String `tfsdk:"tenant"`
-	SecretsFile types.String `tfsdk:"secrets_file"`
+	Username          types.String `tfsdk:"username"`
+	Password          types.String `tfsdk:"password"`
+	URL               types.String `tfsdk:"url"`
+	AuthMethod        types.String `tfsdk:"auth_method"`
+	Tenant            types.String `tfsdk:"tenant"`
+	SecretsFile       types.String `tfsdk:"secrets_file"`
+	Profile           types.String `tfsdk:"profile"`
+	TokenCache        types.Bool   `tfsdk:"token_cache"`
+	AccessToken       types.String `tfsdk:"access_token"`
+	CredentialProcess types.String `tfsdk:"credential_process"`
+	ClientID          types.String `tfsdk:"client_id"`
+	ClientSecret      types.String `tfsdk:"client_secret"`
+	MaxRetries        types.Int64  `tfsdk:"max_retries"`
+	RetryMaxWait      types.String `tfsdk:"retry_max_wait"`
+
+	MaxConcurrentRequests types.Int64  `tfsdk:"max_concurrent_requests"`
+	RequestTimeout        types.String `tfsdk:"request_timeout"`
+
+	OAuthCallbackHost types.String `tfsdk:"oauth_callback_host"`
+	OAuthCallbackPort types.Int64  `tfsdk:"oauth_callback_port"`
+	LoginTimeout      types.String `tfsdk:"login_timeout"`
+
+	CACertFile         types.String `tfsdk:"ca_cert_file"`
+	CACertPEM          types.String `tfsdk:"ca_cert_pem"`
+	ClientCertFile     types.String `tfsdk:"client_cert_file"`
+	ClientCertPEM      types.String `tfsdk:"client_cert_pem"`
+	ClientKeyFile      types.String `tfsdk:"client_key_file"`
+	ClientKeyPEM       types.String `tfsdk:"client_key_pem"`
+	TLSMinVersion      types.String `tfsdk:"tls_min_version"`
+	InsecureSkipVerify types.Bool   `tfsdk:"insecure_skip_verify"`
+
+	ProxyURL      types.String `tfsdk:"proxy_url"`
+	NoProxy       types.String `tfsdk:"no_proxy"`
+	ProxyUsername types.String `tfsdk:"proxy_username"`
+	ProxyPassword types.String `tfsdk:"proxy_password"`
+
+	DefaultLayerType types.String `tfsdk:"default_layer_type"`
+	DefaultLayerID   types.String `tfsdk:"default_layer_id"`
 }
 
+// maxPort is the highest TCP port number
+const maxPort = 65535
+
 func (p *COPProvider) Metadata(_ context.Context, _ provider.MetadataRequest, resp *provider.MetadataResponse) {
 	resp.TypeName = "observability"
 	resp.Version = p.version
@@ -50,12 +92,18 @@ func (p *COPProvider) Schema(_ context.Context, _ provider.SchemaRequest, resp *
 	resp.Schema = schema.Schema{
 		Attributes: map[string]schema.Attribute{
 			"auth_method": schema.StringAttribute{
-				MarkdownDescription: "Authentication type selected for observability API requests. Possible values(oauth, headless, service-principal)",
-				Required:            true,
+				MarkdownDescription: "Authentication type selected for observability API requests. " +
+					"Possible values(oauth, oauth-device, headless, service-principal, agent-principal, access-token, " +
+					"credential-process). " +
+					"Required unless set by profile, access_token, credential_process or client_id",
+				Optional: true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(supportedAuthMethods...),
+				},
 			},
 			"tenant": schema.StringAttribute{
-				MarkdownDescription: "Tenant ID used to make requests to API",
-				Required:            true,
+				MarkdownDescription: "Tenant ID used to make requests to API. Required unless set by profile",
+				Optional:            true,
 			},
 			"username": schema.StringAttribute{
 				MarkdownDescription: "Username to authenticate using headless",
@@ -71,7 +119,169 @@ func (p *COPProvider) Schema(_ context.Context, _ provider.SchemaRequest, resp *
 				Optional:            true,
 			},
 			"secrets_file": schema.StringAttribute{
-				MarkdownDescription: "Path to secrets file to authenticate using service-principal ",
+				MarkdownDescription: "Path to secrets file to authenticate using service-principal or agent-principal. " +
+					"Service principal credentials files can be JSON or YAML",
+				Optional: true,
+			},
+			"client_id": schema.StringAttribute{
+				MarkdownDescription: "Client ID to authenticate using service-principal instead of secrets_file, " +
+					"implies the service-principal auth_method. Can also be set with the COP_CLIENT_ID env var",
+				Optional: true,
+			},
+			"client_secret": schema.StringAttribute{
+				MarkdownDescription: "Secret of the client_id to authenticate using service-principal. " +
+					"Can also be set with the COP_CLIENT_SECRET env var",
+				Optional:  true,
+				Sensitive: true,
+			},
+			"profile": schema.StringAttribute{
+				MarkdownDescription: "Name of the fsoc profile to take url, tenant, auth_method, username and secrets_file from, " +
+					"along with the tokens fsoc cached for it. Attributes set explicitly override the profile values. " +
+					"The fsoc config file is read from FSOC_CONFIG or ~/.fsoc",
+				Optional: true,
+			},
+			"access_token": schema.StringAttribute{
+				MarkdownDescription: "Access token obtained elsewhere (e.g. minted by a CI system) to use instead of logging in, " +
+					"implies the access-token auth_method. It is not refreshed, the provider fails if it has expired. " +
+					"Can also be set with the COP_ACCESS_TOKEN env var",
+				Optional:  true,
+				Sensitive: true,
+			},
+			"credential_process": schema.StringAttribute{
+				MarkdownDescription: "Command run through the shell to obtain the access token, e.g. a helper brokering the credentials, " +
+					"implies the credential-process auth_method. It must print a JSON document with access_token and optionally " +
+					"expires_at (RFC 3339) or expires_in (seconds), and is run again once the token is about to expire. " +
+					"Can also be set with the COP_CREDENTIAL_PROCESS env var",
+				Optional: true,
+			},
+			"token_cache": schema.BoolAttribute{
+				MarkdownDescription: "Whether to cache the access and refresh tokens on disk (readable by the current user only) " +
+					"so that subsequent runs reuse or silently refresh them instead of logging in again, e.g. through the browser. " +
+					"Defaults to true for the oauth and oauth-device auth_method, false for the others",
+				Optional: true,
+			},
+			"oauth_callback_host": schema.StringAttribute{
+				MarkdownDescription: "Host the local server receiving the oauth login callback listens on. Defaults to 127.0.0.1",
+				Optional:            true,
+			},
+			"oauth_callback_port": schema.Int64Attribute{
+				MarkdownDescription: "Port the local server receiving the oauth login callback listens on. Defaults to 3101, " +
+					"0 picks a free port",
+				Optional: true,
+				Validators: []validator.Int64{
+					int64validator.Between(0, maxPort),
+				},
+			},
+			"login_timeout": schema.StringAttribute{
+				MarkdownDescription: "How long to wait for the oauth login to be completed in the browser, e.g. \"2m\". Defaults to 5m",
+				Optional:            true,
+				Validators: []validator.String{
+					IsValidDuration{},
+				},
+			},
+			"ca_cert_file": schema.StringAttribute{
+				MarkdownDescription: "Path to a PEM file with the CA certificates to trust on top of the system ones, " +
+					"e.g. for private cloud endpoints. Conflicts with ca_cert_pem",
+				Optional: true,
+			},
+			"ca_cert_pem": schema.StringAttribute{
+				MarkdownDescription: "PEM encoded CA certificates to trust on top of the system ones. Conflicts with ca_cert_file",
+				Optional:            true,
+			},
+			"client_cert_file": schema.StringAttribute{
+				MarkdownDescription: "Path to a PEM file with the client certificate presented to mutual TLS gateways, " +
+					"requires client_key_file or client_key_pem",
+				Optional: true,
+			},
+			"client_cert_pem": schema.StringAttribute{
+				MarkdownDescription: "PEM encoded client certificate presented to mutual TLS gateways, " +
+					"requires client_key_file or client_key_pem",
+				Optional: true,
+			},
+			"client_key_file": schema.StringAttribute{
+				MarkdownDescription: "Path to a PEM file with the private key of the client certificate",
+				Optional:            true,
+			},
+			"client_key_pem": schema.StringAttribute{
+				MarkdownDescription: "PEM encoded private key of the client certificate",
+				Optional:            true,
+				Sensitive:           true,
+			},
+			"tls_min_version": schema.StringAttribute{
+				MarkdownDescription: "Minimum TLS version of the connections to the platform, 1.2 or 1.3. Defaults to 1.2",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOf("1.2", "1.3"),
+				},
+			},
+			"insecure_skip_verify": schema.BoolAttribute{
+				MarkdownDescription: "Whether to skip the verification of the platform certificate. " +
+					"Connections are not secure, only use it in labs. Defaults to false",
+				Optional: true,
+			},
+			"proxy_url": schema.StringAttribute{
+				MarkdownDescription: "URL of the proxy to reach the platform through, e.g. <http://proxy.example.com:3128>, " +
+					"for both the login and the API requests of this provider instance. " +
+					"Defaults to the HTTPS_PROXY and HTTP_PROXY env vars",
+				Optional: true,
+			},
+			"no_proxy": schema.StringAttribute{
+				MarkdownDescription: "Comma-separated hosts, domains and CIDRs reached without the proxy, " +
+					"e.g. \"internal.example.com,10.0.0.0/8\". Defaults to the NO_PROXY env var",
+				Optional: true,
+			},
+			"proxy_username": schema.StringAttribute{
+				MarkdownDescription: "Username to authenticate to the proxy, requires proxy_url",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.AlsoRequires(path.MatchRoot("proxy_url")),
+				},
+			},
+			"proxy_password": schema.StringAttribute{
+				MarkdownDescription: "Password to authenticate to the proxy, requires proxy_username",
+				Optional:            true,
+				Sensitive:           true,
+				Validators: []validator.String{
+					stringvalidator.AlsoRequires(path.MatchRoot("proxy_username")),
+				},
+			},
+			"max_retries": schema.Int64Attribute{
+				MarkdownDescription: "Number of times a failed API request is retried when the platform is throttling or " +
+					"temporarily unavailable. Defaults to 3, 0 disables retries",
+				Optional: true,
+				Validators: []validator.Int64{
+					int64validator.AtLeast(0),
+				},
+			},
+			"retry_max_wait": schema.StringAttribute{
+				MarkdownDescription: "Maximum delay between two retries of a failed API request, e.g. \"30s\". Defaults to 30s",
+				Optional:            true,
+				Validators: []validator.String{
+					IsValidDuration{},
+				},
+			},
+			"max_concurrent_requests": schema.Int64Attribute{
+				MarkdownDescription: "Maximum number of API requests (logins included) in flight at once, the others wait for " +
+					"their turn, e.g. to avoid being throttled with a high terraform -parallelism. Defaults to 10, 0 disables the limit",
+				Optional: true,
+				Validators: []validator.Int64{
+					int64validator.AtLeast(0),
+				},
+			},
+			"request_timeout": schema.StringAttribute{
+				MarkdownDescription: "How long to wait for the response to an API request, each retry getting the same time, " +
+					"e.g. \"30s\". Defaults to 1m",
+				Optional: true,
+				Validators: []validator.String{
+					IsValidDuration{},
+				},
+			},
+			"default_layer_type": schema.StringAttribute{
+				MarkdownDescription: "Layer type of the objects whose resource does not set layer_type, e.g. \"TENANT\"",
+				Optional:            true,
+			},
+			"default_layer_id": schema.StringAttribute{
+				MarkdownDescription: "Layer ID of the objects whose resource does not set layer_id. Defaults to the tenant",
 				Optional:            true,
 			},
 		},
@@ -130,7 +340,7 @@ func (p *COPProvider) Configure(ctx context.Context, req provider.ConfigureReque
 		)
 	}
 
-	if data.URL.IsUnknown() {
+	if data.SecretsFile.IsUnknown() {
 		resp.Diagnostics.AddAttributeError(
 			path.Root("secrets_file"),
 			"Unknown observability API secrets_file",
@@ -138,130 +348,425 @@ func (p *COPProvider) Configure(ctx context.Context, req provider.ConfigureReque
 		)
 	}
 
+	if data.CredentialProcess.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("credential_process"),
+			"Unknown observability API credential_process",
+			"Please make sure you configure the credential_process field",
+		)
+	}
+
+	if data.AccessToken.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("access_token"),
+			"Unknown observability API access_token",
+			"Please make sure you configure the access_token field",
+		)
+	}
+
+	if data.ClientID.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("client_id"),
+			"Unknown observability API client_id",
+			"Please make sure you configure the client_id field",
+		)
+	}
+
+	if data.ClientSecret.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("client_secret"),
+			"Unknown observability API client_secret",
+			"Please make sure you configure the client_secret field",
+		)
+	}
+
+	if data.DefaultLayerType.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("default_layer_type"),
+			"Unknown observability API default_layer_type",
+			"Please make sure you configure the default_layer_type field",
+		)
+	}
+
+	if data.DefaultLayerID.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("default_layer_id"),
+			"Unknown observability API default_layer_id",
+			"Please make sure you configure the default_layer_id field",
+		)
+	}
+
+	if data.Profile.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("profile"),
+			"Unknown observability API profile",
+			"Please make sure you configure the profile field",
+		)
+	}
+
+	if data.CACertFile.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("ca_cert_file"),
+			"Unknown observability API ca_cert_file",
+			"Please make sure you configure the ca_cert_file field",
+		)
+	}
+
+	if data.CACertPEM.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("ca_cert_pem"),
+			"Unknown observability API ca_cert_pem",
+			"Please make sure you configure the ca_cert_pem field",
+		)
+	}
+
+	if data.ClientCertFile.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("client_cert_file"),
+			"Unknown observability API client_cert_file",
+			"Please make sure you configure the client_cert_file field",
+		)
+	}
+
+	if data.ClientCertPEM.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("client_cert_pem"),
+			"Unknown observability API client_cert_pem",
+			"Please make sure you configure the client_cert_pem field",
+		)
+	}
+
+	if data.ClientKeyFile.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("client_key_file"),
+			"Unknown observability API client_key_file",
+			"Please make sure you configure the client_key_file field",
+		)
+	}
+
+	if data.ClientKeyPEM.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("client_key_pem"),
+			"Unknown observability API client_key_pem",
+			"Please make sure you configure the client_key_pem field",
+		)
+	}
+
+	if data.TLSMinVersion.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("tls_min_version"),
+			"Unknown observability API tls_min_version",
+			"Please make sure you configure the tls_min_version field",
+		)
+	}
+
+	if data.InsecureSkipVerify.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("insecure_skip_verify"),
+			"Unknown observability API insecure_skip_verify",
+			"Please make sure you configure the insecure_skip_verify field",
+		)
+	}
+
+	if data.ProxyURL.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("proxy_url"),
+			"Unknown observability API proxy_url",
+			"Please make sure you configure the proxy_url field",
+		)
+	}
+
+	if data.NoProxy.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("no_proxy"),
+			"Unknown observability API no_proxy",
+			"Please make sure you configure the no_proxy field",
+		)
+	}
+
+	if data.ProxyUsername.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("proxy_username"),
+			"Unknown observability API proxy_username",
+			"Please make sure you configure the proxy_username field",
+		)
+	}
+
+	if data.ProxyPassword.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("proxy_password"),
+			"Unknown observability API proxy_password",
+			"Please make sure you configure the proxy_password field",
+		)
+	}
+
+	if data.MaxConcurrentRequests.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("max_concurrent_requests"),
+			"Unknown observability API max_concurrent_requests",
+			"Please make sure you configure the max_concurrent_requests field",
+		)
+	}
+
+	if data.RequestTimeout.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("request_timeout"),
+			"Unknown observability API request_timeout",
+			"Please make sure you configure the request_timeout field",
+		)
+	}
+
+	if data.MaxRetries.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("max_retries"),
+			"Unknown observability API max_retries",
+			"Please make sure you configure the max_retries field",
+		)
+	}
+
+	if data.RetryMaxWait.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("retry_max_wait"),
+			"Unknown observability API retry_max_wait",
+			"Please make sure you configure the retry_max_wait field",
+		)
+	}
+
+	if data.TokenCache.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("token_cache"),
+			"Unknown observability API token_cache",
+			"Please make sure you configure the token_cache field",
+		)
+	}
+
+	if data.OAuthCallbackHost.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("oauth_callback_host"),
+			"Unknown observability API oauth_callback_host",
+			"Please make sure you configure the oauth_callback_host field",
+		)
+	}
+
+	if data.OAuthCallbackPort.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("oauth_callback_port"),
+			"Unknown observability API oauth_callback_port",
+			"Please make sure you configure the oauth_callback_port field",
+		)
+	}
+
+	if data.LoginTimeout.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("login_timeout"),
+			"Unknown observability API login_timeout",
+			"Please make sure you configure the login_timeout field",
+		)
+	}
+
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	// Default values to environment variables, but override
-	// with Terraform configuration value if set.
-
-	username := os.Getenv("COP_USERNAME")
-	password := os.Getenv("COP_PASSWORD")
-	authMethod := os.Getenv("COP_AUTH_METHOD")
-	tenantID := os.Getenv("COP_TENANT")
-	url := os.Getenv("URL")
-	secretsFile := os.Getenv("SECRETS_FILE")
+	// Default values to the fsoc profile, if any, then to environment variables,
+	// but override with Terraform configuration value if set.
+	cfg, diags := resolveAuthConfig(ctx, &data)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
 	tflog.Debug(ctx, fmt.Sprintf("Terraform username is %s", data.Username))
-	tflog.Debug(ctx, fmt.Sprintf("Terraform password is %s", data.Password))
 	tflog.Debug(ctx, fmt.Sprintf("Terraform url is %s", data.URL))
 	tflog.Debug(ctx, fmt.Sprintf("Terraform tenant is %s", data.Tenant))
 	tflog.Debug(ctx, fmt.Sprintf("Terraform secrets file path is %s", data.SecretsFile))
 	tflog.Debug(ctx, fmt.Sprintf("Terraform auth_method is %s", data.AuthMethod))
-	tflog.Debug(ctx, fmt.Sprintf("Terraform auth_method FROM ENV is %s", authMethod))
+	tflog.Debug(ctx, fmt.Sprintf("Terraform auth_method resolved to %s", cfg.authMethod))
 
-	if !data.Username.IsNull() {
-		username = data.Username.ValueString()
+	// exit if any of the required attributes is missing
+	// based on our current auth_method
+	cfg.validate(&data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
-	if !data.Password.IsNull() {
-		password = data.Password.ValueString()
+	maxRetries := client.DefaultMaxRetries
+	if !data.MaxRetries.IsNull() {
+		maxRetries = int(data.MaxRetries.ValueInt64())
 	}
 
-	if !data.URL.IsNull() {
-		url = data.URL.ValueString()
+	retryMaxWait := client.DefaultRetryMaxWait
+	if !data.RetryMaxWait.IsNull() {
+		// already checked by the IsValidDuration validator
+		retryMaxWait, _ = time.ParseDuration(data.RetryMaxWait.ValueString())
 	}
 
-	if !data.Tenant.IsNull() {
-		tenantID = data.Tenant.ValueString()
+	maxConcurrentRequests := client.DefaultMaxConcurrentRequests
+	if !data.MaxConcurrentRequests.IsNull() {
+		maxConcurrentRequests = int(data.MaxConcurrentRequests.ValueInt64())
 	}
 
-	if !data.AuthMethod.IsNull() {
-		authMethod = data.AuthMethod.ValueString()
+	requestTimeout := client.DefaultRequestTimeout
+	if !data.RequestTimeout.IsNull() {
+		// already checked by the IsValidDuration validator
+		requestTimeout, _ = time.ParseDuration(data.RequestTimeout.ValueString())
 	}
 
-	if !data.SecretsFile.IsNull() {
-		secretsFile = data.SecretsFile.ValueString()
+	// only used by the oauth login
+	callbackHost, callbackPort, _ := net.SplitHostPort(client.DefaultOAuthCallbackAddr)
+	if !data.OAuthCallbackHost.IsNull() {
+		callbackHost = data.OAuthCallbackHost.ValueString()
+	}
+	if !data.OAuthCallbackPort.IsNull() {
+		callbackPort = strconv.FormatInt(data.OAuthCallbackPort.ValueInt64(), 10)
 	}
 
-	if authMethod == "" {
-		resp.Diagnostics.AddAttributeError(
-			path.Root("auth_method"),
-			"Missing observability API auth_method",
-			"SET the COP_AUTH_METHOD env var or the config",
-		)
-		tflog.Error(ctx, "Missing or empty value for auth_method attribute")
+	loginTimeout := client.DefaultLoginTimeout
+	if !data.LoginTimeout.IsNull() {
+		// already checked by the IsValidDuration validator
+		loginTimeout, _ = time.ParseDuration(data.LoginTimeout.ValueString())
 	}
 
-	switch authMethod {
-	case "oauth":
-		if url == "" {
-			resp.Diagnostics.AddAttributeError(
-				path.Root("url"),
-				"Missing observability API url",
-				"SET the COP_URL env var or the config",
-			)
-		}
+	httpClient, err := newHTTPClient(&data)
+	if err != nil {
+		resp.Diagnostics.AddError("Invalid observability API TLS or proxy settings", err.Error())
+		return
+	}
 
-		if tenantID == "" {
-			resp.Diagnostics.AddAttributeError(
-				path.Root("tenant"),
-				"Missing observability API tenant",
-				"SET the COP_TENANT env var or the config",
-			)
-		}
-	case "headless":
-		if username == "" {
-			resp.Diagnostics.AddAttributeError(
-				path.Root("username"),
-				"Missing observability API username",
-				"SET the COP_USERNAME env var or the config",
-			)
+	// auth_method was checked by validate
+	var authOption client.Option
+	switch cfg.authMethod {
+	case client.AuthMethodOAuth:
+		authOption = client.WithOAuth()
+	case client.AuthMethodDeviceCode:
+		authOption = client.WithDeviceCode()
+	case client.AuthMethodHeadless:
+		authOption = client.WithHeadless(cfg.username, cfg.password)
+	case client.AuthMethodServicePrincipal:
+		authOption = client.WithServicePrincipal(cfg.secretsFile)
+		if cfg.clientID != "" {
+			authOption = client.WithClientCredentials(cfg.clientID, cfg.clientSecret)
 		}
+	case client.AuthMethodAgentPrincipal:
+		authOption = client.WithAgentPrincipal(cfg.secretsFile)
+	case client.AuthMethodAccessToken:
+		authOption = client.WithAccessToken(cfg.accessToken)
+	case client.AuthMethodCredentialProcess:
+		authOption = client.WithCredentialProcess(cfg.credentialProcess)
+	}
 
-		if password == "" {
-			resp.Diagnostics.AddAttributeError(
-				path.Root("password"),
-				"Missing observability API password",
-				"SET the COP_PASSWORD env var or the config",
-			)
-		}
-	case "service-principal":
-		if secretsFile == "" {
-			resp.Diagnostics.AddAttributeError(
-				path.Root("secrets_file"),
-				"Missing observability API secrets_file",
-				"SET the SECRETS_FILE env var or the config",
-			)
+	opts := []client.Option{
+		authOption,
+		client.WithHTTPClient(httpClient),
+		client.WithRetries(maxRetries, retryMaxWait),
+		client.WithRequestLimits(maxConcurrentRequests, requestTimeout),
+		client.WithOAuthCallback(net.JoinHostPort(callbackHost, callbackPort)),
+		client.WithLoginTimeout(loginTimeout),
+		client.WithDefaultLayer(data.DefaultLayerType.ValueString(), data.DefaultLayerID.ValueString()),
+		// also used by oauth when no browser can be opened
+		client.WithDevicePrompt(func(auth *client.DeviceAuthorization) { promptDeviceLogin(ctx, auth) }),
+	}
+
+	// cache the tokens on disk so subsequent runs do not have to log in again; by default only for the
+	// interactive logins, the other methods log in without user interaction anyway
+	cacheTokens := cfg.authMethod == client.AuthMethodOAuth || cfg.authMethod == client.AuthMethodDeviceCode
+	if !data.TokenCache.IsNull() {
+		cacheTokens = data.TokenCache.ValueBool()
+	}
+	if cacheTokens {
+		cacheDir, cacheErr := client.DefaultTokenCacheDir()
+		if cacheErr != nil {
+			tflog.Warn(ctx, fmt.Sprintf("Token cache disabled: %s", cacheErr.Error()))
+		} else {
+			opts = append(opts, client.WithTokenCache(cacheDir))
 		}
 	}
 
-	// exit if any of the required attributes is missing
-	// based on our current auth_method
-	if resp.Diagnostics.HasError() {
+	// reuse the tokens fsoc cached for the profile, unless the settings were overridden to another tenant or method
+	profile := cfg.profile
+	if profile.Token != "" && profile.URL == cfg.url && profile.Tenant == cfg.tenantID && profile.AuthMethod == cfg.authMethod {
+		tflog.Debug(ctx, "Reusing the access token cached by fsoc")
+		opts = append(opts, client.WithCachedTokens(profile.Token, profile.RefreshToken))
+	}
+
+	observabilityClient, err := client.New(cfg.url, cfg.tenantID, opts...)
+	if err != nil {
+		resp.Diagnostics.AddError("Unable to Create observability client", err.Error())
 		return
 	}
 
-	appdClient := &api.AppdClient{
-		AuthMethod: authMethod,
-		Username:   username,
-		Password:   password,
-		URL:        url,
-		Tenant:     tenantID,
-		SecretFile: secretsFile,
-		APIClient:  http.DefaultClient,
+	// fail right away rather than handing a client without a token to the resources
+	if err = observabilityClient.Login(ctx); err != nil {
+		tflog.Error(ctx, fmt.Sprintf("Failed to authenticate to observability client: %s", err.Error()))
+		addLoginDiagnostic(&resp.Diagnostics, cfg, err)
+		return
+	}
+
+	tflog.Debug(ctx, fmt.Sprintf("Successful authentication to observability client using %s", observabilityClient.AuthMethod()))
+
+	// catch a misconfigured tenant now rather than through 403s later in the apply
+	checkTokenTenant(ctx, &resp.Diagnostics, observabilityClient)
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
-	err := appdClient.Login()
+	resp.DataSourceData = observabilityClient
+	resp.ResourceData = observabilityClient
+}
+
+// checkTokenTenant logs the principal the access token was issued to and reports an error if it was issued
+// for another tenant than the configured one
+func checkTokenTenant(ctx context.Context, diags *diag.Diagnostics, observabilityClient *client.Client) {
+	info, err := observabilityClient.TokenInfo()
 	if err != nil {
-		tflog.Error(ctx, fmt.Sprintf("Failed to authenticate to observability client: %s", err.Error()))
+		tflog.Debug(ctx, fmt.Sprintf("Unable to decode the access token claims: %s", err.Error()))
+		return
 	}
 
-	tflog.Debug(ctx, fmt.Sprintf("Successful authentication to observability client using %s", appdClient.AuthMethod))
+	tflog.Info(ctx, fmt.Sprintf("Acting as principal %s on tenant %s", info.Principal, observabilityClient.Tenant()))
+	if !info.ExpiresAt.IsZero() {
+		tflog.Debug(ctx, fmt.Sprintf("Access token expires at %s", info.ExpiresAt.Format(time.RFC3339)))
+	}
 
-	// TODO change this to a real client
-	resp.DataSourceData = appdClient
-	resp.ResourceData = appdClient
+	if info.Tenant != "" && !strings.EqualFold(info.Tenant, observabilityClient.Tenant()) {
+		diags.AddAttributeError(
+			path.Root("tenant"),
+			"Mismatched observability API tenant",
+			fmt.Sprintf("The access token of principal %s was issued for tenant %q, but the tenant is set to %q. "+
+				"SET the COP_TENANT env var, the config or the profile to the tenant of the credentials.",
+				info.Principal, info.Tenant, observabilityClient.Tenant()),
+		)
+	}
+}
+
+// promptDeviceLogin tells the user how to approve a device code login. Terraform does not show the provider
+// output, so the instructions go straight to the terminal when there is one, and to the logs in any case.
+func promptDeviceLogin(ctx context.Context, auth *client.DeviceAuthorization) {
+	msg := fmt.Sprintf("To log in to the observability platform, visit %s and enter the code %s",
+		auth.VerificationURI, auth.UserCode)
+	if auth.VerificationURIComplete != "" {
+		msg += fmt.Sprintf(" (or visit %s)", auth.VerificationURIComplete)
+	}
+	tflog.Warn(ctx, msg)
+
+	tty, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0)
+	if err != nil {
+		return // no terminal, e.g. on Windows or in CI
+	}
+	defer tty.Close()
+	fmt.Fprintln(tty, msg)
+}
+
+// configValue returns the value of a provider attribute if set, else the value of the environment
+// variable envVar if set, else fallback
+func configValue(attr types.String, envVar, fallback string) string {
+	if !attr.IsNull() {
+		return attr.ValueString()
+	}
+	if value := os.Getenv(envVar); value != "" {
+		return value
+	}
+	return fallback
 }
 
 func (p *COPProvider) Resources(_ context.Context) []func() resource.Resource {
@@ -275,6 +780,7 @@ func (p *COPProvider) Resources(_ context.Context) []func() resource.Resource {
 func (p *COPProvider) DataSources(_ context.Context) []func() datasource.DataSource {
 	return []func() datasource.DataSource{
 		NewKnowledgeTypeDataSource,
+		NewKnowledgeObjectsDataSource,
 	}
 }
 
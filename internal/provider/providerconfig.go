@@ -0,0 +1,336 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+//
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/cisco-open/terraform-provider-observability/pkg/client"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/providervalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/provider"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+var _ provider.ProviderWithConfigValidators = &COPProvider{}
+
+// supportedAuthMethods are the possible values of auth_method
+var supportedAuthMethods = []string{
+	client.AuthMethodOAuth,
+	client.AuthMethodDeviceCode,
+	client.AuthMethodHeadless,
+	client.AuthMethodServicePrincipal,
+	client.AuthMethodAgentPrincipal,
+	client.AuthMethodAccessToken,
+	client.AuthMethodCredentialProcess,
+}
+
+// methodAttributes lists the credential attributes and the auth methods using them
+var methodAttributes = map[string][]string{
+	"username":           {client.AuthMethodHeadless},
+	"password":           {client.AuthMethodHeadless},
+	"secrets_file":       {client.AuthMethodServicePrincipal, client.AuthMethodAgentPrincipal},
+	"client_id":          {client.AuthMethodServicePrincipal},
+	"client_secret":      {client.AuthMethodServicePrincipal},
+	"access_token":       {client.AuthMethodAccessToken},
+	"credential_process": {client.AuthMethodCredentialProcess},
+}
+
+// ConfigValidators validates the provider configuration at plan time, before Configure logs in
+func (p *COPProvider) ConfigValidators(_ context.Context) []provider.ConfigValidator {
+	return []provider.ConfigValidator{
+		providervalidator.Conflicting(path.MatchRoot("access_token"), path.MatchRoot("credential_process")),
+		providervalidator.Conflicting(path.MatchRoot("access_token"), path.MatchRoot("client_id")),
+		providervalidator.Conflicting(path.MatchRoot("credential_process"), path.MatchRoot("client_id")),
+		providervalidator.Conflicting(path.MatchRoot("client_id"), path.MatchRoot("secrets_file")),
+		providervalidator.Conflicting(path.MatchRoot("ca_cert_file"), path.MatchRoot("ca_cert_pem")),
+		providervalidator.Conflicting(path.MatchRoot("client_cert_file"), path.MatchRoot("client_cert_pem")),
+		providervalidator.Conflicting(path.MatchRoot("client_key_file"), path.MatchRoot("client_key_pem")),
+		authConfigValidator{},
+	}
+}
+
+// authConfig holds the provider settings resolved from the Terraform configuration, the environment
+// variables and the fsoc profile, in that order of precedence
+type authConfig struct {
+	profile           *client.FsocProfile
+	authMethod        string
+	tenantID          string
+	url               string
+	username          string
+	password          string
+	secretsFile       string
+	accessToken       string
+	credentialProcess string
+	clientID          string
+	clientSecret      string
+}
+
+// resolveAuthConfig resolves the provider settings of the configuration data, loading the fsoc profile if any
+func resolveAuthConfig(ctx context.Context, data *COPProviderModel) (*authConfig, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	profile := &client.FsocProfile{}
+	profileName := configValue(data.Profile, "COP_PROFILE", "")
+	if profileName != "" {
+		var err error
+		profile, err = client.LoadFsocProfile(profileName)
+		if err != nil {
+			diags.AddAttributeError(path.Root("profile"), "Unable to Load fsoc profile", err.Error())
+			return nil, diags
+		}
+		tflog.Debug(ctx, fmt.Sprintf("Using fsoc profile %s", profileName))
+	}
+
+	cfg := &authConfig{
+		profile:           profile,
+		username:          configValue(data.Username, "COP_USERNAME", profile.User),
+		password:          configValue(data.Password, "COP_PASSWORD", ""),
+		authMethod:        configValue(data.AuthMethod, "COP_AUTH_METHOD", profile.AuthMethod),
+		tenantID:          configValue(data.Tenant, "COP_TENANT", profile.Tenant),
+		url:               configValue(data.URL, "URL", profile.URL),
+		secretsFile:       configValue(data.SecretsFile, "SECRETS_FILE", profile.SecretFile),
+		accessToken:       configValue(data.AccessToken, "COP_ACCESS_TOKEN", ""),
+		credentialProcess: configValue(data.CredentialProcess, "COP_CREDENTIAL_PROCESS", ""),
+		clientID:          configValue(data.ClientID, "COP_CLIENT_ID", ""),
+		clientSecret:      configValue(data.ClientSecret, "COP_CLIENT_SECRET", ""),
+	}
+
+	// an access token is all that is needed to make requests
+	if cfg.accessToken != "" && data.AuthMethod.IsNull() {
+		cfg.authMethod = client.AuthMethodAccessToken
+	} else if cfg.credentialProcess != "" && data.AuthMethod.IsNull() {
+		cfg.authMethod = client.AuthMethodCredentialProcess
+	} else if cfg.clientID != "" && data.AuthMethod.IsNull() {
+		cfg.authMethod = client.AuthMethodServicePrincipal
+	}
+
+	return cfg, diags
+}
+
+// validate reports the settings missing for the resolved auth method, and the credential attributes
+// configured for another method
+//
+//nolint:funlen,gocyclo // one case per auth method
+func (c *authConfig) validate(data *COPProviderModel, diags *diag.Diagnostics) {
+	switch {
+	case c.authMethod == "":
+		diags.AddAttributeError(
+			path.Root("auth_method"),
+			"Missing observability API auth_method",
+			"SET the COP_AUTH_METHOD env var, the config or a profile",
+		)
+	case !slices.Contains(supportedAuthMethods, c.authMethod):
+		diags.AddAttributeError(
+			path.Root("auth_method"),
+			"Unsupported observability API auth_method",
+			fmt.Sprintf("Unsupported auth_method %q (set by the config, the COP_AUTH_METHOD env var or a profile), "+
+				"possible values(%s)", c.authMethod, strings.Join(supportedAuthMethods, ", ")),
+		)
+		return
+	}
+
+	if c.tenantID == "" {
+		diags.AddAttributeError(
+			path.Root("tenant"),
+			"Missing observability API tenant",
+			"SET the COP_TENANT env var, the config or a profile",
+		)
+	}
+
+	if c.url == "" {
+		diags.AddAttributeError(
+			path.Root("url"),
+			"Missing observability API url",
+			"SET the URL env var, the config or a profile",
+		)
+	}
+
+	switch c.authMethod {
+	case client.AuthMethodHeadless:
+		if c.username == "" {
+			diags.AddAttributeError(
+				path.Root("username"),
+				"Missing observability API username",
+				"SET the COP_USERNAME env var or the config",
+			)
+		}
+
+		if c.password == "" {
+			diags.AddAttributeError(
+				path.Root("password"),
+				"Missing observability API password",
+				"SET the COP_PASSWORD env var or the config",
+			)
+		}
+	case client.AuthMethodServicePrincipal:
+		switch {
+		case c.clientID != "" && c.clientSecret == "":
+			diags.AddAttributeError(
+				path.Root("client_secret"),
+				"Missing observability API client_secret",
+				"SET the COP_CLIENT_SECRET env var or the config",
+			)
+		case c.clientID == "" && c.clientSecret != "":
+			diags.AddAttributeError(
+				path.Root("client_id"),
+				"Missing observability API client_id",
+				"SET the COP_CLIENT_ID env var or the config",
+			)
+		case c.clientID == "" && c.secretsFile == "":
+			diags.AddAttributeError(
+				path.Root("secrets_file"),
+				"Missing observability API secrets_file",
+				"SET the SECRETS_FILE env var or the config, or client_id and client_secret",
+			)
+		}
+	case client.AuthMethodAgentPrincipal:
+		if c.secretsFile == "" {
+			diags.AddAttributeError(
+				path.Root("secrets_file"),
+				"Missing observability API secrets_file",
+				"SET the SECRETS_FILE env var or the config",
+			)
+		}
+	case client.AuthMethodCredentialProcess:
+		if c.credentialProcess == "" {
+			diags.AddAttributeError(
+				path.Root("credential_process"),
+				"Missing observability API credential_process",
+				"SET the COP_CREDENTIAL_PROCESS env var or the config",
+			)
+		}
+	case client.AuthMethodAccessToken:
+		if c.accessToken == "" {
+			diags.AddAttributeError(
+				path.Root("access_token"),
+				"Missing observability API access_token",
+				"SET the COP_ACCESS_TOKEN env var or the config",
+			)
+		}
+	}
+
+	// credentials configured for another method are most likely a mistake, environment variables
+	// are not checked since they may be shared with other tools
+	configured := []struct {
+		attr  string
+		value types.String
+	}{
+		{"username", data.Username},
+		{"password", data.Password},
+		{"secrets_file", data.SecretsFile},
+		{"client_id", data.ClientID},
+		{"client_secret", data.ClientSecret},
+		{"access_token", data.AccessToken},
+		{"credential_process", data.CredentialProcess},
+	}
+	for _, setting := range configured {
+		if setting.value.IsNull() || slices.Contains(methodAttributes[setting.attr], c.authMethod) {
+			continue
+		}
+		diags.AddAttributeError(
+			path.Root(setting.attr),
+			fmt.Sprintf("Conflicting observability API %s", setting.attr),
+			fmt.Sprintf("%s is not used by the %s auth_method, it is only used by %s. Remove it or change auth_method.",
+				setting.attr, c.authMethod, strings.Join(methodAttributes[setting.attr], " and ")),
+		)
+	}
+}
+
+// authConfigValidator checks the settings required by the auth method are set, taking the environment
+// variables and the fsoc profile into account like Configure does
+type authConfigValidator struct{}
+
+func (v authConfigValidator) Description(_ context.Context) string {
+	return "auth_method is supported and the settings it requires are set"
+}
+
+func (v authConfigValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v authConfigValidator) ValidateProvider(ctx context.Context, req provider.ValidateConfigRequest,
+	resp *provider.ValidateConfigResponse) {
+	var data COPProviderModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// values depending on other resources are only known when configuring the provider
+	for _, value := range []types.String{
+		data.AuthMethod, data.Tenant, data.URL, data.Username, data.Password, data.SecretsFile, data.Profile,
+		data.AccessToken, data.CredentialProcess, data.ClientID, data.ClientSecret,
+	} {
+		if value.IsUnknown() {
+			return
+		}
+	}
+
+	cfg, diags := resolveAuthConfig(ctx, &data)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	cfg.validate(&data, &resp.Diagnostics)
+}
+
+// newHTTPClient builds the HTTP client with the TLS and proxy settings of the configuration
+func newHTTPClient(data *COPProviderModel) (*http.Client, error) {
+	return client.NewHTTPClient(&client.TransportOptions{
+		ProxyURL:           data.ProxyURL.ValueString(),
+		NoProxy:            data.NoProxy.ValueString(),
+		ProxyUsername:      data.ProxyUsername.ValueString(),
+		ProxyPassword:      data.ProxyPassword.ValueString(),
+		CACertFile:         data.CACertFile.ValueString(),
+		CACertPEM:          data.CACertPEM.ValueString(),
+		ClientCertFile:     data.ClientCertFile.ValueString(),
+		ClientCertPEM:      data.ClientCertPEM.ValueString(),
+		ClientKeyFile:      data.ClientKeyFile.ValueString(),
+		ClientKeyPEM:       data.ClientKeyPEM.ValueString(),
+		MinTLSVersion:      data.TLSMinVersion.ValueString(),
+		InsecureSkipVerify: data.InsecureSkipVerify.ValueBool(),
+	})
+}
+
+// addLoginDiagnostic appends an error diagnostic for a failed login, pointing at the settings to fix
+func addLoginDiagnostic(diags *diag.Diagnostics, cfg *authConfig, err error) {
+	switch {
+	case errors.Is(err, client.ErrTokenExpired):
+		diags.AddAttributeError(
+			path.Root("access_token"),
+			"Expired observability API access_token",
+			fmt.Sprintf("%s. Mint a new token and SET the COP_ACCESS_TOKEN env var or the config.", err.Error()),
+		)
+	case errors.Is(err, client.ErrInvalidCredentials):
+		diags.AddAttributeError(
+			path.Root("password"),
+			"Invalid observability API credentials",
+			fmt.Sprintf("%s. Check the username and password of tenant %s at %s.", err.Error(), cfg.tenantID, cfg.url),
+		)
+	case errors.Is(err, client.ErrMFARequired):
+		diags.AddAttributeError(
+			path.Root("auth_method"),
+			"Multi-factor authentication required",
+			fmt.Sprintf("%s. SET auth_method to oauth, or oauth-device on machines without a browser.", err.Error()),
+		)
+	default:
+		diags.AddError(
+			"Unable to authenticate to the observability platform",
+			fmt.Sprintf("Login to tenant %s at %s using the %s auth_method failed: %s\n\n"+
+				"Check url, tenant and the credentials of the auth_method.", cfg.tenantID, cfg.url, cfg.authMethod, err.Error()),
+		)
+	}
+}
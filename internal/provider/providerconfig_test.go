@@ -0,0 +1,249 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+//
+// SPDX-License-Identifier: MPL-2.0
+
+//go:build unit
+
+package provider_test
+
+import (
+	"context"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/cisco-open/terraform-provider-observability/internal/provider"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	fwprovider "github.com/hashicorp/terraform-plugin-framework/provider"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// providerEnvVars are the environment variables read by the provider configuration
+var providerEnvVars = []string{
+	"COP_USERNAME", "COP_PASSWORD", "COP_AUTH_METHOD", "COP_TENANT", "URL", "SECRETS_FILE", "COP_PROFILE",
+	"COP_ACCESS_TOKEN", "COP_CREDENTIAL_PROCESS", "COP_CLIENT_ID", "COP_CLIENT_SECRET",
+}
+
+// validateProviderConfig runs the provider config validators on a configuration setting the given attributes
+func validateProviderConfig(t *testing.T, attrs map[string]tftypes.Value) diag.Diagnostics {
+	t.Helper()
+	ctx := context.Background()
+
+	p, ok := provider.New("test")().(fwprovider.ProviderWithConfigValidators)
+	if !ok {
+		t.Fatalf("The provider does not validate its configuration")
+	}
+
+	var schemaResp fwprovider.SchemaResponse
+	p.Schema(ctx, fwprovider.SchemaRequest{}, &schemaResp)
+	objectType, ok := schemaResp.Schema.Type().TerraformType(ctx).(tftypes.Object)
+	if !ok {
+		t.Fatalf("Unexpected provider schema type")
+	}
+
+	values := make(map[string]tftypes.Value, len(objectType.AttributeTypes))
+	for name, attrType := range objectType.AttributeTypes {
+		values[name] = tftypes.NewValue(attrType, nil)
+	}
+	for name, value := range attrs {
+		values[name] = value
+	}
+
+	req := fwprovider.ValidateConfigRequest{
+		Config: tfsdk.Config{Schema: schemaResp.Schema, Raw: tftypes.NewValue(objectType, values)},
+	}
+	// like the framework, give each validator its own response since some overwrite its diagnostics
+	var diags diag.Diagnostics
+	for _, v := range p.ConfigValidators(ctx) {
+		var resp fwprovider.ValidateConfigResponse
+		v.ValidateProvider(ctx, req, &resp)
+		diags.Append(resp.Diagnostics...)
+	}
+	return diags
+}
+
+func str(value string) tftypes.Value {
+	return tftypes.NewValue(tftypes.String, value)
+}
+
+func TestProviderConfigValidators(t *testing.T) {
+	tests := []struct {
+		name     string
+		attrs    map[string]tftypes.Value
+		env      map[string]string
+		expected string // summary of the expected error, empty if valid
+	}{
+		{
+			name: "headless",
+			attrs: map[string]tftypes.Value{
+				"auth_method": str("headless"), "tenant": str("t"), "url": str("https://example.com"), "username": str("u"),
+				"password": str("p"),
+			},
+		},
+		{
+			name:     "headless without password",
+			attrs:    map[string]tftypes.Value{"auth_method": str("headless"), "tenant": str("t"), "username": str("u")},
+			expected: "Missing observability API password",
+		},
+		{
+			name:  "headless with password from env",
+			attrs: map[string]tftypes.Value{"auth_method": str("headless"), "tenant": str("t"), "username": str("u")},
+			env:   map[string]string{"COP_PASSWORD": "p", "URL": "https://example.com"},
+		},
+		{
+			name:     "missing tenant",
+			attrs:    map[string]tftypes.Value{"auth_method": str("oauth"), "url": str("https://example.com")},
+			expected: "Missing observability API tenant",
+		},
+		{
+			name:  "access token implies the auth method",
+			attrs: map[string]tftypes.Value{"tenant": str("t"), "url": str("https://example.com"), "access_token": str("token")},
+		},
+		{
+			name:     "missing url",
+			attrs:    map[string]tftypes.Value{"auth_method": str("service-principal"), "tenant": str("t"), "secrets_file": str("f")},
+			expected: "Missing observability API url",
+		},
+		{
+			name:     "unsupported auth method from env",
+			attrs:    map[string]tftypes.Value{"tenant": str("t")},
+			env:      map[string]string{"COP_AUTH_METHOD": "bogus"},
+			expected: "Unsupported observability API auth_method",
+		},
+		{
+			name: "credentials of another auth method",
+			attrs: map[string]tftypes.Value{
+				"auth_method": str("service-principal"), "tenant": str("t"), "secrets_file": str("f"), "password": str("p"),
+			},
+			expected: "Conflicting observability API password",
+		},
+		{
+			name:     "conflicting attributes",
+			attrs:    map[string]tftypes.Value{"tenant": str("t"), "client_id": str("id"), "secrets_file": str("f")},
+			expected: "Invalid Attribute Combination",
+		},
+		{
+			name: "CA certificate set twice",
+			attrs: map[string]tftypes.Value{
+				"tenant": str("t"), "access_token": str("token"), "ca_cert_file": str("ca.pem"), "ca_cert_pem": str("pem"),
+			},
+			expected: "Invalid Attribute Combination",
+		},
+		{
+			name: "unknown values are checked when configuring",
+			attrs: map[string]tftypes.Value{
+				"auth_method": tftypes.NewValue(tftypes.String, tftypes.UnknownValue), "tenant": str("t"),
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// keep the environment of the machine running the tests out of the way
+			for _, env := range providerEnvVars {
+				t.Setenv(env, test.env[env])
+			}
+			diags := validateProviderConfig(t, test.attrs)
+
+			var summaries []string
+			for _, d := range diags.Errors() {
+				summaries = append(summaries, d.Summary())
+			}
+			got := strings.Join(summaries, "; ")
+
+			switch {
+			case test.expected == "" && got != "":
+				t.Errorf("Expected a valid configuration, got errors: %s", got)
+			case test.expected != "" && !strings.Contains(got, test.expected):
+				t.Errorf("Expected the error %q, got: %s", test.expected, got)
+			}
+		})
+	}
+}
+
+// configureProvider configures the provider with a configuration setting the given attributes
+func configureProvider(t *testing.T, attrs map[string]tftypes.Value) *fwprovider.ConfigureResponse {
+	t.Helper()
+	ctx := context.Background()
+	p := provider.New("test")()
+
+	var schemaResp fwprovider.SchemaResponse
+	p.Schema(ctx, fwprovider.SchemaRequest{}, &schemaResp)
+	objectType, ok := schemaResp.Schema.Type().TerraformType(ctx).(tftypes.Object)
+	if !ok {
+		t.Fatalf("Unexpected provider schema type")
+	}
+
+	values := make(map[string]tftypes.Value, len(objectType.AttributeTypes))
+	for name, attrType := range objectType.AttributeTypes {
+		values[name] = tftypes.NewValue(attrType, nil)
+	}
+	for name, value := range attrs {
+		values[name] = value
+	}
+
+	req := fwprovider.ConfigureRequest{
+		Config: tfsdk.Config{Schema: schemaResp.Schema, Raw: tftypes.NewValue(objectType, values)},
+	}
+	var resp fwprovider.ConfigureResponse
+	p.Configure(ctx, req, &resp)
+	return &resp
+}
+
+func TestProviderConfigureUnknownValues(t *testing.T) {
+	// values only known after apply, e.g. read from another resource, cannot configure the client
+	attrs := map[string]tftypes.Type{
+		"ca_cert_file": tftypes.String, "ca_cert_pem": tftypes.String, "client_cert_file": tftypes.String,
+		"client_cert_pem": tftypes.String, "client_key_file": tftypes.String, "client_key_pem": tftypes.String,
+		"tls_min_version": tftypes.String, "insecure_skip_verify": tftypes.Bool, "proxy_url": tftypes.String,
+		"no_proxy": tftypes.String, "proxy_username": tftypes.String, "proxy_password": tftypes.String,
+		"max_concurrent_requests": tftypes.Number, "request_timeout": tftypes.String, "max_retries": tftypes.Number,
+		"retry_max_wait": tftypes.String, "token_cache": tftypes.Bool, "oauth_callback_host": tftypes.String,
+		"oauth_callback_port": tftypes.Number, "login_timeout": tftypes.String, "secrets_file": tftypes.String,
+	}
+	for attr, attrType := range attrs {
+		resp := configureProvider(t, map[string]tftypes.Value{attr: tftypes.NewValue(attrType, tftypes.UnknownValue)})
+
+		expected := "Unknown observability API " + attr
+		if errs := resp.Diagnostics.Errors(); len(errs) != 1 || errs[0].Summary() != expected {
+			t.Errorf("%s: got diagnostics %v, expected %q", attr, resp.Diagnostics, expected)
+		}
+	}
+}
+
+func TestProviderConfigureTokenTenant(t *testing.T) {
+	for _, env := range providerEnvVars {
+		t.Setenv(env, "")
+	}
+
+	tests := []struct {
+		name     string
+		claims   string
+		expected string // summary of the expected error, empty if valid
+	}{
+		{"matching tenant", `{"sub": "ci", "tenant": "` + layerTestTenant + `"}`, ""},
+		{"tenant in another case", `{"sub": "ci", "tenantId": "` + strings.ToUpper(layerTestTenant) + `"}`, ""},
+		{"no tenant claim", `{"sub": "ci"}`, ""},
+		{"another tenant", `{"sub": "ci", "tenant_id": "another-tenant"}`, "Mismatched observability API tenant"},
+	}
+
+	for _, test := range tests {
+		accessToken := "eyJhbGciOiJSUzI1NiJ9." + base64.RawURLEncoding.EncodeToString([]byte(test.claims)) + ".signature"
+		resp := configureProvider(t, map[string]tftypes.Value{
+			"url":          str("https://mytenant.observe.appdynamics.com"),
+			"tenant":       str(layerTestTenant),
+			"access_token": str(accessToken),
+		})
+
+		var summaries []string
+		for _, d := range resp.Diagnostics.Errors() {
+			summaries = append(summaries, d.Summary())
+		}
+		if got := strings.Join(summaries, "; "); got != test.expected {
+			t.Errorf("%s: got errors %q, expected %q", test.name, got, test.expected)
+		}
+	}
+}
@@ -9,11 +9,15 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/cisco-open/terraform-provider-observability/internal/api"
+	"github.com/cisco-open/terraform-provider-observability/pkg/client"
 
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
@@ -22,6 +26,17 @@ import (
 // Ensure provider defined types fully satisfy framework interfaces.
 var _ resource.Resource = &KnowledgeObjectResource{}
 var _ resource.ResourceWithImportState = &KnowledgeObjectResource{}
+var _ resource.ResourceWithModifyPlan = &KnowledgeObjectResource{}
+
+// update strategies supported by the object resource
+const (
+	updateStrategyReplace    = "replace"
+	updateStrategyMergePatch = "merge_patch"
+)
+
+// appliedDataKey is the private state key of the data as last applied by Terraform, the fields merge_patch
+// is allowed to remove
+const appliedDataKey = "applied_data"
 
 func NewKnowledgeObjectResource() resource.Resource {
 	return &KnowledgeObjectResource{}
@@ -29,18 +44,20 @@ func NewKnowledgeObjectResource() resource.Resource {
 
 // KnowledgeObjectResource defines the resource implementation.
 type KnowledgeObjectResource struct {
-	client *api.AppdClient
+	client *client.Client
 }
 
 // KnowledgeObjectResourceModel describes the resource data model.
 type KnowledgeObjectResourceModel struct {
-	TypeName  types.String `tfsdk:"type_name"`
-	ObjectID  types.String `tfsdk:"object_id"`
-	LayerID   types.String `tfsdk:"layer_id"`
-	LayerType types.String `tfsdk:"layer_type"`
-	Data      types.String `tfsdk:"data"`
-	ImportID  types.String `tfsdk:"import_id"`
-	ID        types.String `tfsdk:"id"`
+	TypeName       types.String `tfsdk:"type_name"`
+	ObjectID       types.String `tfsdk:"object_id"`
+	LayerID        types.String `tfsdk:"layer_id"`
+	LayerType      types.String `tfsdk:"layer_type"`
+	Data           types.String `tfsdk:"data"`
+	ImportID       types.String `tfsdk:"import_id"`
+	UpdateStrategy types.String `tfsdk:"update_strategy"`
+	Version        types.String `tfsdk:"version"`
+	ID             types.String `tfsdk:"id"`
 }
 
 func (r *KnowledgeObjectResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -63,12 +80,24 @@ func (r *KnowledgeObjectResource) Schema(_ context.Context, _ resource.SchemaReq
 				Optional:            true,
 			},
 			"layer_id": schema.StringAttribute{
-				MarkdownDescription: "Specifies the layer ID where the object resides",
-				Required:            true,
+				MarkdownDescription: "Specifies the layer ID where the object resides. Defaults to the provider default_layer_id",
+				Optional:            true,
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					// an object cannot move to another layer; keep the planned default of an unconfigured layer
+					// known until ModifyPlan, which replaces the object if the default changed
+					stringplanmodifier.UseStateForUnknown(),
+					stringplanmodifier.RequiresReplace(),
+				},
 			},
 			"layer_type": schema.StringAttribute{
-				MarkdownDescription: "Specifies the layer type where the object resides",
-				Required:            true,
+				MarkdownDescription: "Specifies the layer type where the object resides. Defaults to the provider default_layer_type",
+				Optional:            true,
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+					stringplanmodifier.RequiresReplace(),
+				},
 			},
 			"data": schema.StringAttribute{
 				MarkdownDescription: "JSON schema of the returned object",
@@ -81,6 +110,19 @@ func (r *KnowledgeObjectResource) Schema(_ context.Context, _ resource.SchemaReq
 				MarkdownDescription: "ID used when doing import operation on an object",
 				Optional:            true,
 			},
+			"update_strategy": schema.StringAttribute{
+				MarkdownDescription: "How changes to data are sent to the platform. Possible values(replace, merge_patch). " +
+					"`replace` (default) replaces the whole object, `merge_patch` only sends the fields that changed " +
+					"since the last apply, leaving fields managed outside Terraform untouched",
+				Optional: true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(updateStrategyReplace, updateStrategyMergePatch),
+				},
+			},
+			"version": schema.StringAttribute{
+				MarkdownDescription: "Version (ETag) of the object when it was last read, used to detect changes made outside Terraform",
+				Computed:            true,
+			},
 			"id": schema.StringAttribute{
 				MarkdownDescription: "Used to provide compatibility for testing framework",
 				Computed:            true,
@@ -95,16 +137,23 @@ func (r *KnowledgeObjectResource) Configure(_ context.Context, req resource.Conf
 		return
 	}
 
-	client, ok := req.ProviderData.(*api.AppdClient)
+	observabilityClient, ok := req.ProviderData.(*client.Client)
 	if !ok {
 		resp.Diagnostics.AddError(
 			"Unexpected Resource Configure Type",
-			fmt.Sprintf("Expected *api.AppdClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+			fmt.Sprintf("Expected *client.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
 		)
 		return
 	}
 
-	r.client = client
+	r.client = observabilityClient
+}
+
+// ModifyPlan plans the provider default layer for the objects whose layer is not configured
+//
+//nolint:gocritic // Terraform framework requires the method signature to be as is
+func (r *KnowledgeObjectResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	planDefaultLayer(ctx, r.client, &req, resp)
 }
 
 //nolint:gocritic // Terraform framework requires the method signature to be as is
@@ -125,15 +174,19 @@ func (r *KnowledgeObjectResource) Create(ctx context.Context, req resource.Creat
 	layerID := data.LayerID.ValueString()
 	jsonPayload := []byte(data.Data.ValueString())
 
-	err := r.client.CreateObject(typeName, layerID, layerType, jsonPayload)
+	createdID, version, err := r.client.CreateObject(ctx, typeName, layerID, layerType, jsonPayload)
 	if err != nil {
-		resp.Diagnostics.AddError(
-			fmt.Sprintf("Unable to Read type %s", typeName),
-			err.Error(),
-		)
+		addAPIErrorDiagnostic(&resp.Diagnostics, fmt.Sprintf("Unable to Create object of type %s", typeName), err)
 		return
 	}
 
+	// record the version of the newly created object, its ID may have been assigned by the platform
+	objID := data.ObjectID.ValueString()
+	if objID == "" {
+		objID = createdID
+	}
+	data.Version = r.objectVersion(ctx, &resp.Diagnostics, version, typeName, objID, layerID, layerType)
+
 	// set the placeholder value for testing purposses
 	data.ID = types.StringValue("placeholder")
 
@@ -142,6 +195,7 @@ func (r *KnowledgeObjectResource) Create(ctx context.Context, req resource.Creat
 	tflog.Debug(ctx, "created a resource")
 
 	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.Private.SetKey(ctx, appliedDataKey, appliedData(&data))...)
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
@@ -186,12 +240,15 @@ func (r *KnowledgeObjectResource) Read(ctx context.Context, req resource.ReadReq
 	tflog.Debug(ctx, fmt.Sprintf("data payload %s", currentDataPayload))
 	tflog.Debug(ctx, fmt.Sprintf("import identifier is %s", importIdentifier))
 
-	result, err := r.client.GetObject(typeName, objID, layerID, layerType)
+	result, version, err := r.client.GetObjectWithETag(ctx, typeName, objID, layerID, layerType)
+	if client.IsNotFound(err) {
+		// the object was deleted outside of terraform, drop it from the state so it gets recreated
+		tflog.Warn(ctx, fmt.Sprintf("Object of type %s with id %s not found, removing it from state", typeName, objID))
+		resp.State.RemoveResource(ctx)
+		return
+	}
 	if err != nil {
-		resp.Diagnostics.AddError(
-			fmt.Sprintf("Unable to Read object of type %s with id %s", typeName, objID),
-			err.Error(),
-		)
+		addAPIErrorDiagnostic(&resp.Diagnostics, fmt.Sprintf("Unable to Read object of type %s with id %s", typeName, objID), err)
 		return
 	}
 
@@ -268,6 +325,7 @@ func (r *KnowledgeObjectResource) Read(ctx context.Context, req resource.ReadReq
 	data.ObjectID = types.StringValue(objID)
 	data.LayerType = types.StringValue(layerType)
 	data.LayerID = types.StringValue(layerID)
+	data.Version = types.StringValue(version)
 
 	// Save updated data into Terraform state
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
@@ -276,11 +334,14 @@ func (r *KnowledgeObjectResource) Read(ctx context.Context, req resource.ReadReq
 //nolint:gocritic // Terraform framework requires the method signature to be as is
 func (r *KnowledgeObjectResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
 	tflog.Debug(ctx, "Update method invoked")
-	var data KnowledgeObjectResourceModel
+	var data, state KnowledgeObjectResourceModel
 
 	// Read Terraform plan data into the model
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
 
+	// Read the version known to Terraform from the prior state
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+
 	if resp.Diagnostics.HasError() {
 		return
 	}
@@ -291,20 +352,37 @@ func (r *KnowledgeObjectResource) Update(ctx context.Context, req resource.Updat
 	layerType := data.LayerType.ValueString()
 	layerID := data.LayerID.ValueString()
 	jsonPayload := []byte(data.Data.ValueString())
-
-	err := r.client.UpdateObject(typeName, objID, layerID, layerType, jsonPayload)
+	ifMatch := client.IfMatch(state.Version.ValueString())
+
+	var version string
+	var err error
+	if data.UpdateStrategy.ValueString() == updateStrategyMergePatch {
+		managed, privateDiags := req.Private.GetKey(ctx, appliedDataKey)
+		resp.Diagnostics.Append(privateDiags...)
+		version, err = r.patchObject(ctx, &resp.Diagnostics, &data, &state, managed, ifMatch)
+	} else {
+		version, err = r.client.UpdateObject(ctx, typeName, objID, layerID, layerType, jsonPayload, ifMatch)
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if client.IsPreconditionFailed(err) {
+		addObjectChangedDiagnostic(&resp.Diagnostics, typeName, objID)
+		return
+	}
 	if err != nil {
-		resp.Diagnostics.AddError(
-			fmt.Sprintf("Unable to Read type %s", typeName),
-			err.Error(),
-		)
+		addAPIErrorDiagnostic(&resp.Diagnostics, fmt.Sprintf("Unable to Update object of type %s with id %s", typeName, objID), err)
 		return
 	}
 
+	// record the version of the updated object
+	data.Version = r.objectVersion(ctx, &resp.Diagnostics, version, typeName, objID, layerID, layerType)
+
 	// set the placeholder value for testing purposses
 	data.ID = types.StringValue("placeholder")
 
 	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.Private.SetKey(ctx, appliedDataKey, appliedData(&data))...)
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
@@ -326,16 +404,85 @@ func (r *KnowledgeObjectResource) Delete(ctx context.Context, req resource.Delet
 	layerID := data.LayerID.ValueString()
 	layerType := data.LayerType.ValueString()
 
-	err := r.client.DeleteObject(typeName, objID, layerID, layerType)
+	err := r.client.DeleteObject(ctx, typeName, objID, layerID, layerType, client.IfMatch(data.Version.ValueString()))
+	if client.IsNotFound(err) {
+		// already gone, nothing left to delete
+		return
+	}
+	if client.IsPreconditionFailed(err) {
+		addObjectChangedDiagnostic(&resp.Diagnostics, typeName, objID)
+		return
+	}
 	if err != nil {
-		resp.Diagnostics.AddError(
-			fmt.Sprintf("Unable to Delete object of type %s with id %s", typeName, objID),
-			err.Error(),
-		)
+		addAPIErrorDiagnostic(&resp.Diagnostics, fmt.Sprintf("Unable to Delete object of type %s with id %s", typeName, objID), err)
 		return
 	}
 }
 
+// patchObject sends only the difference between the data known to Terraform and the planned data
+// as a JSON merge patch, so fields not managed by Terraform are left as they are on the platform.
+// Only the fields in managed, the data last applied, are removed: after an import the state holds the
+// whole object, whose fields missing from the configuration must not be deleted.
+// It returns the version of the object after the patch.
+func (r *KnowledgeObjectResource) patchObject(ctx context.Context, diags *diag.Diagnostics,
+	plan, state *KnowledgeObjectResourceModel, managed []byte, ifMatch client.RequestOption) (string, error) {
+	typeName := plan.TypeName.ValueString()
+	objID := plan.ObjectID.ValueString()
+
+	prior := state.Data.ValueString()
+	if prior == "" {
+		prior = "{}"
+	}
+	planned := plan.Data.ValueString()
+	if planned == "" {
+		planned = "{}"
+	}
+
+	patch, err := client.CreateManagedMergePatch([]byte(prior), []byte(planned), managed)
+	if err != nil {
+		diags.AddError(fmt.Sprintf("Unable to compute the patch for object of type %s with id %s", typeName, objID), err.Error())
+		return "", nil
+	}
+	if string(patch) == "{}" {
+		tflog.Debug(ctx, "data is unchanged, skipping the patch request")
+		return state.Version.ValueString(), nil
+	}
+
+	tflog.Debug(ctx, fmt.Sprintf("patching object with %s", string(patch)))
+	return r.client.PatchObject(ctx, typeName, objID, plan.LayerID.ValueString(), plan.LayerType.ValueString(), patch, ifMatch)
+}
+
+// appliedData returns the data applied by Terraform, recorded in the private state for merge_patch
+func appliedData(data *KnowledgeObjectResourceModel) []byte {
+	if data.Data.ValueString() == "" {
+		return []byte("{}")
+	}
+	return []byte(data.Data.ValueString())
+}
+
+// objectVersion returns the version (ETag) reported by the write of an object. If there is none, the
+// version is read back, which may miss a concurrent change; failing to read it is reported as a warning
+// only since the write itself succeeded.
+func (r *KnowledgeObjectResource) objectVersion(ctx context.Context, diags *diag.Diagnostics, writtenVersion,
+	typeName, objID, layerID, layerType string) types.String {
+	if writtenVersion != "" {
+		return types.StringValue(writtenVersion)
+	}
+	if objID == "" {
+		return types.StringValue("")
+	}
+
+	_, version, err := r.client.GetObjectWithETag(ctx, typeName, objID, layerID, layerType)
+	if err != nil {
+		diags.AddWarning(
+			fmt.Sprintf("Unable to Read version of object of type %s with id %s", typeName, objID),
+			fmt.Sprintf("Changes made outside Terraform will not be detected until the next refresh: %s", err.Error()),
+		)
+		return types.StringValue("")
+	}
+	return types.StringValue(version)
+}
+
 func (r *KnowledgeObjectResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
 	resource.ImportStatePassthroughID(ctx, path.Root("import_id"), req, resp)
 }
@@ -0,0 +1,351 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+//
+// SPDX-License-Identifier: MPL-2.0
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/cisco-open/terraform-provider-observability/internal/api"
+)
+
+// supported authentication methods
+const (
+	AuthMethodOAuth             = "oauth"              // interactive login through the browser
+	AuthMethodHeadless          = "headless"           // username and password
+	AuthMethodServicePrincipal  = "service-principal"  // service principal credentials file
+	AuthMethodAgentPrincipal    = "agent-principal"    // agent principal credentials file
+	AuthMethodAccessToken       = "access-token"       // access token obtained elsewhere
+	AuthMethodDeviceCode        = "oauth-device"       // login approved in a browser on another machine
+	AuthMethodCredentialProcess = "credential-process" // access token printed by a helper command
+)
+
+// OAuth login defaults, see WithOAuthCallback and WithLoginTimeout
+const (
+	DefaultOAuthCallbackAddr = api.DefaultOAuthCallbackAddr
+	DefaultLoginTimeout      = api.DefaultLoginTimeout
+)
+
+// retry defaults, see WithRetries
+const (
+	DefaultMaxRetries   = api.DefaultMaxRetries
+	DefaultRetryMaxWait = api.DefaultRetryMaxWait
+)
+
+// request limits defaults, see WithRequestLimits
+const (
+	DefaultMaxConcurrentRequests = api.DefaultMaxConcurrentRequests
+	DefaultRequestTimeout        = api.DefaultRequestTimeout
+)
+
+// Client is a Cisco Observability Platform client bound to a single tenant.
+// It is safe for concurrent use once created.
+type Client struct {
+	ac *api.AppdClient
+
+	defaultLayerType string // see WithDefaultLayer
+	defaultLayerID   string
+}
+
+// Option configures a Client, see New
+type Option func(*Client)
+
+// New creates a client for the tenant reachable at platformURL (e.g. https://mytenant.observe.appdynamics.com).
+// Exactly one authentication option (WithOAuth, WithDeviceCode, WithHeadless, WithServicePrincipal,
+// WithClientCredentials, WithAgentPrincipal, WithAccessToken or WithCredentialProcess) must be given;
+// no request is made until Login is called.
+func New(platformURL, tenant string, opts ...Option) (*Client, error) {
+	if platformURL == "" {
+		return nil, errors.New("the platform URL is required")
+	}
+	if u, err := url.Parse(platformURL); err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid platform URL %q, expected e.g. https://mytenant.observe.appdynamics.com", platformURL)
+	}
+
+	c := &Client{
+		ac: &api.AppdClient{
+			URL:          strings.TrimSuffix(platformURL, "/"),
+			Tenant:       tenant,
+			APIClient:    http.DefaultClient,
+			MaxRetries:   DefaultMaxRetries,
+			RetryMaxWait: DefaultRetryMaxWait,
+
+			MaxConcurrentRequests: DefaultMaxConcurrentRequests,
+			RequestTimeout:        DefaultRequestTimeout,
+		},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	switch c.ac.AuthMethod {
+	case AuthMethodOAuth, AuthMethodHeadless, AuthMethodServicePrincipal, AuthMethodAgentPrincipal, AuthMethodAccessToken,
+		AuthMethodDeviceCode, AuthMethodCredentialProcess:
+	case "":
+		return nil, errors.New("no authentication method configured")
+	default:
+		return nil, fmt.Errorf("unsupported authentication method %q", c.ac.AuthMethod)
+	}
+	return c, nil
+}
+
+// WithOAuth authenticates interactively, opening the platform login page in the browser. When no browser
+// can be launched (e.g. over SSH) it falls back to the device code flow, see WithDeviceCode.
+func WithOAuth() Option {
+	return func(c *Client) {
+		c.ac.AuthMethod = AuthMethodOAuth
+	}
+}
+
+// WithOAuthCallback sets the host:port the local server receiving the OAuth callback listens on,
+// DefaultOAuthCallbackAddr by default. Port 0 picks a free port, which the platform has to accept as redirect URI.
+func WithOAuthCallback(addr string) Option {
+	return func(c *Client) {
+		c.ac.CallbackAddr = addr
+	}
+}
+
+// WithLoginTimeout sets how long Login waits for the user to log in through the browser, DefaultLoginTimeout by default
+func WithLoginTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.ac.LoginTimeout = timeout
+	}
+}
+
+// WithBrowserOpener replaces how the OAuth login page is opened, the system browser by default.
+// An error makes Login fall back to the device code flow.
+func WithBrowserOpener(openBrowser func(uri string) error) Option {
+	return func(c *Client) {
+		c.ac.OpenBrowser = openBrowser
+	}
+}
+
+// WithDeviceCode authenticates interactively without a local browser: Login hands a verification URI and a
+// user code to the prompt (see WithDevicePrompt), then waits until the user approved the login from a
+// browser on any machine
+func WithDeviceCode() Option {
+	return func(c *Client) {
+		c.ac.AuthMethod = AuthMethodDeviceCode
+	}
+}
+
+// WithDevicePrompt sets how the user is told to approve a device code login, by default it is logged
+func WithDevicePrompt(prompt func(*DeviceAuthorization)) Option {
+	return func(c *Client) {
+		if prompt == nil {
+			c.ac.DevicePrompt = nil
+			return
+		}
+		c.ac.DevicePrompt = func(auth *api.DeviceAuthorization) {
+			converted := DeviceAuthorization(*auth)
+			prompt(&converted)
+		}
+	}
+}
+
+// WithHeadless authenticates with the username and password of a platform user
+func WithHeadless(username, password string) Option {
+	return func(c *Client) {
+		c.ac.AuthMethod = AuthMethodHeadless
+		c.ac.Username = username
+		c.ac.Password = password
+	}
+}
+
+// WithServicePrincipal authenticates with the service principal credentials file downloaded from the platform,
+// JSON or YAML with either "Client ID" and "Secret", client_id and client_secret, or clientId and clientSecret
+func WithServicePrincipal(credentialsFile string) Option {
+	return func(c *Client) {
+		c.ac.AuthMethod = AuthMethodServicePrincipal
+		c.ac.SecretFile = credentialsFile
+	}
+}
+
+// WithClientCredentials authenticates as a service principal with its client ID and secret, e.g. injected
+// as environment variables by a secrets manager, instead of the credentials file
+func WithClientCredentials(clientID, clientSecret string) Option {
+	return func(c *Client) {
+		c.ac.AuthMethod = AuthMethodServicePrincipal
+		c.ac.ClientID = clientID
+		c.ac.ClientSecret = clientSecret
+	}
+}
+
+// WithAgentPrincipal authenticates with the agent principal credentials file (YAML or JSON with clientId,
+// clientSecret, tokenUrl and tenantId) downloaded from the platform when configuring agents
+func WithAgentPrincipal(credentialsFile string) Option {
+	return func(c *Client) {
+		c.ac.AuthMethod = AuthMethodAgentPrincipal
+		c.ac.SecretFile = credentialsFile
+	}
+}
+
+// WithAccessToken uses an access token minted elsewhere (e.g. by a CI system) instead of authenticating.
+// Login only checks the token has not expired (ErrTokenExpired); it cannot be refreshed.
+func WithAccessToken(accessToken string) Option {
+	return func(c *Client) {
+		c.ac.AuthMethod = AuthMethodAccessToken
+		c.ac.Token = accessToken
+	}
+}
+
+// WithCredentialProcess obtains the access token by running command through the shell, e.g. a helper
+// brokering the credentials. The command prints a JSON document with access_token and optionally
+// expires_at (RFC 3339) or expires_in (seconds) on its stdout; it is run again once the token is about to expire.
+func WithCredentialProcess(command string) Option {
+	return func(c *Client) {
+		c.ac.AuthMethod = AuthMethodCredentialProcess
+		c.ac.CredentialProcess = command
+	}
+}
+
+// WithCachedTokens reuses tokens obtained earlier (e.g. cached by fsoc, see LoadFsocProfile) instead of
+// authenticating on Login, unless the access token expired; they are refreshed using the configured
+// authentication method once about to expire or rejected
+func WithCachedTokens(accessToken, refreshToken string) Option {
+	return func(c *Client) {
+		c.ac.UseCachedTokens(accessToken, refreshToken)
+	}
+}
+
+// WithTokenCache caches the tokens on disk in dir (see DefaultTokenCacheDir), readable by the current user
+// only, so that later clients for the same URL, tenant and principal reuse or silently refresh them on Login
+// instead of authenticating again (e.g. opening the browser with WithOAuth)
+func WithTokenCache(dir string) Option {
+	return func(c *Client) {
+		c.ac.TokenCacheDir = dir
+	}
+}
+
+// DefaultTokenCacheDir returns the token cache directory in the user cache dir
+func DefaultTokenCacheDir() (string, error) {
+	return api.DefaultTokenCacheDir()
+}
+
+// TransportOptions configures the connections of the HTTP client built by NewHTTPClient, e.g. to trust
+// a private CA, present a client certificate to a mutual TLS gateway or go through a proxy
+type TransportOptions struct {
+	ProxyURL      string // proxy for all requests, e.g. http://proxy:3128; the HTTPS_PROXY env var and co. if empty
+	NoProxy       string // comma-separated hosts, domains and CIDRs reached directly, same syntax as NO_PROXY
+	ProxyUsername string // credentials for proxies requiring basic authentication
+	ProxyPassword string
+
+	CACertFile         string // additional CAs trusted on top of the system ones
+	CACertPEM          string
+	ClientCertFile     string // client certificate for mutual TLS, requires the matching key
+	ClientCertPEM      string
+	ClientKeyFile      string
+	ClientKeyPEM       string
+	MinTLSVersion      string // "1.2" (default) or "1.3"
+	InsecureSkipVerify bool   // do not verify the server certificate, for labs only
+}
+
+// NewHTTPClient builds an HTTP client with the given transport options, to be passed to WithHTTPClient
+func NewHTTPClient(opts *TransportOptions) (*http.Client, error) {
+	var apiOpts api.TransportOptions
+	if opts != nil {
+		apiOpts = api.TransportOptions(*opts)
+	}
+	return api.NewHTTPClient(&apiOpts)
+}
+
+// WithHTTPClient sets the HTTP client used for all requests, http.DefaultClient by default
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.ac.APIClient = httpClient
+	}
+}
+
+// WithRetries sets how many times a failed knowledge store request is retried (0 disables retries)
+// and the upper bound for the delay between retries; DefaultMaxRetries and DefaultRetryMaxWait by default
+func WithRetries(maxRetries int, maxWait time.Duration) Option {
+	return func(c *Client) {
+		c.ac.MaxRetries = maxRetries
+		c.ac.RetryMaxWait = maxWait
+	}
+}
+
+// WithRequestLimits sets how many requests to the platform (logins included) may be in flight at once, the
+// others waiting for their turn, and the deadline of each request; 0 disables the limit, respectively the deadline.
+// DefaultMaxConcurrentRequests and DefaultRequestTimeout by default.
+func WithRequestLimits(maxConcurrent int, timeout time.Duration) Option {
+	return func(c *Client) {
+		c.ac.MaxConcurrentRequests = maxConcurrent
+		c.ac.RequestTimeout = timeout
+	}
+}
+
+// WithDefaultLayer sets the layer objects are managed in when the caller does not specify one, see DefaultLayer
+func WithDefaultLayer(layerType, layerID string) Option {
+	return func(c *Client) {
+		c.defaultLayerType = layerType
+		c.defaultLayerID = layerID
+	}
+}
+
+// Login authenticates using the configured method and stores the obtained tokens in the client.
+// The access token is subsequently refreshed automatically whenever it is about to expire.
+func (c *Client) Login(ctx context.Context) error {
+	return convertError(c.ac.Login(ctx))
+}
+
+// TokenInfo returns the principal, tenant and expiry claimed by the current access token, e.g. to check the
+// token was issued for the expected tenant after Login. It fails if the token is not a JWT.
+func (c *Client) TokenInfo() (*TokenInfo, error) {
+	info, err := c.ac.TokenInfo()
+	if err != nil {
+		return nil, err
+	}
+	converted := TokenInfo(*info)
+	return &converted, nil
+}
+
+// URL returns the platform URL the client talks to
+func (c *Client) URL() string {
+	return c.ac.URL
+}
+
+// Tenant returns the tenant ID the client is bound to
+func (c *Client) Tenant() string {
+	return c.ac.Tenant
+}
+
+// DefaultLayer returns the layer set by WithDefaultLayer, the layer ID defaulting to the tenant ID.
+// The layer type is empty if none was set.
+func (c *Client) DefaultLayer() (layerType, layerID string) {
+	layerID = c.defaultLayerID
+	if layerID == "" {
+		layerID = c.ac.Tenant
+	}
+	return c.defaultLayerType, layerID
+}
+
+// AuthMethod returns the configured authentication method, one of the AuthMethod constants
+func (c *Client) AuthMethod() string {
+	return c.ac.AuthMethod
+}
+
+// TokenInfo describes who the access token was issued to, see Client.TokenInfo
+type TokenInfo struct {
+	Principal string    // ID of the user or principal the client acts as
+	Tenant    string    // tenant the token was issued for, empty if the token does not say
+	ExpiresAt time.Time // zero if the token does not expire
+}
+
+// DeviceAuthorization describes what the user has to do to approve a device code login:
+// open VerificationURI in any browser and enter UserCode
+type DeviceAuthorization struct {
+	VerificationURI         string
+	VerificationURIComplete string // VerificationURI with the user code already filled in, if the platform provides it
+	UserCode                string
+	ExpiresAt               time.Time
+}
@@ -0,0 +1,140 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+//
+// SPDX-License-Identifier: MPL-2.0
+
+//go:build unit
+
+package client_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cisco-open/terraform-provider-observability/pkg/client"
+)
+
+const (
+	tenant      = "0eb4e853-34fb-4f77-b3fc-b9cd3b462366"
+	credentials = `{"Client ID": "sample-client-id", "Secret": "sample-secret"}`
+	objectType  = "fmm:namespace"
+)
+
+func TestNewValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		url  string
+		opts []client.Option
+	}{
+		{"missing url", "", []client.Option{client.WithOAuth()}},
+		{"relative url", "observe.appdynamics.com", []client.Option{client.WithOAuth()}},
+		{"missing auth method", "https://mytenant.observe.appdynamics.com", nil},
+	}
+
+	for _, test := range tests {
+		if _, err := client.New(test.url, tenant, test.opts...); err == nil {
+			t.Errorf("%s: New returned no error", test.name)
+		}
+	}
+
+	c, err := client.New("https://mytenant.observe.appdynamics.com/", tenant, client.WithHeadless("user", "pass"))
+	if err != nil {
+		t.Fatalf("New returned an error: %v", err)
+	}
+	if c.URL() != "https://mytenant.observe.appdynamics.com" || c.Tenant() != tenant || c.AuthMethod() != client.AuthMethodHeadless {
+		t.Errorf("Got client for %s, tenant %s using %s", c.URL(), c.Tenant(), c.AuthMethod())
+	}
+}
+
+func TestDefaultLayer(t *testing.T) {
+	c, err := client.New("https://mytenant.observe.appdynamics.com", tenant, client.WithOAuth())
+	if err != nil {
+		t.Fatalf("New returned an error: %v", err)
+	}
+	if layerType, layerID := c.DefaultLayer(); layerType != "" || layerID != tenant {
+		t.Errorf("Got default layer %s %s, expected the tenant without layer type", layerType, layerID)
+	}
+
+	c, err = client.New("https://mytenant.observe.appdynamics.com", tenant, client.WithOAuth(),
+		client.WithDefaultLayer("SOLUTION", "sample-solution"))
+	if err != nil {
+		t.Fatalf("New returned an error: %v", err)
+	}
+	if layerType, layerID := c.DefaultLayer(); layerType != "SOLUTION" || layerID != "sample-solution" {
+		t.Errorf("Got default layer %s %s, expected SOLUTION sample-solution", layerType, layerID)
+	}
+}
+
+func TestClientServicePrincipal(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/auth/" + tenant + "/default/oauth2/token":
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"access_token": "sample-token", "expires_in": 3600}`)
+		case "/knowledge-store/v1/objects/" + objectType + "/missing":
+			w.WriteHeader(http.StatusNotFound)
+		case "/knowledge-store/v1/objects/" + objectType:
+			if filter := r.URL.Query().Get("filter"); filter != `data.region eq "us-east-2"` {
+				t.Errorf("Got filter %q", filter)
+			}
+			fmt.Fprint(w, `{"items": [{"id": "sample", "layerType": "TENANT", "data": {"region": "us-east-2"}}], "total": 1}`)
+		case "/knowledge-store/v1/objects/" + objectType + "/sample":
+			if r.Header.Get("Authorization") != "Bearer sample-token" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Header().Set("ETag", `"1"`)
+			fmt.Fprint(w, `{"id": "sample"}`)
+		default:
+			t.Errorf("Unexpected request to %s", r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	credentialsFile := filepath.Join(t.TempDir(), "credentials.json")
+	if err := os.WriteFile(credentialsFile, []byte(credentials), 0o600); err != nil {
+		t.Fatalf("Failed to write the credentials file: %v", err)
+	}
+
+	c, err := client.New(srv.URL, tenant, client.WithServicePrincipal(credentialsFile), client.WithHTTPClient(srv.Client()))
+	if err != nil {
+		t.Fatalf("New returned an error: %v", err)
+	}
+	ctx := context.Background()
+	if err := c.Login(ctx); err != nil {
+		t.Fatalf("Login returned an error: %v", err)
+	}
+
+	obj, etag, err := c.GetObjectWithETag(ctx, objectType, "sample", tenant, "TENANT")
+	if err != nil {
+		t.Fatalf("GetObjectWithETag returned an error: %v", err)
+	}
+	if string(obj) != `{"id": "sample"}` || etag != `"1"` {
+		t.Errorf("Got object %s with version %s", obj, etag)
+	}
+
+	objects, err := c.ListObjects(ctx, objectType, tenant, "TENANT",
+		client.NewObjectQuery().Where("region", client.OpEqual, "us-east-2")).All()
+	if err != nil {
+		t.Fatalf("ListObjects returned an error: %v", err)
+	}
+	if len(objects) != 1 || objects[0].ID != "sample" || string(objects[0].Data) != `{"region": "us-east-2"}` {
+		t.Errorf("Got objects %+v", objects)
+	}
+
+	_, err = c.GetObject(ctx, objectType, "missing", tenant, "TENANT")
+	if !client.IsNotFound(err) {
+		t.Errorf("GetObject returned %v, expected a not found error", err)
+	}
+	var apiErr *client.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound || apiErr.Method != http.MethodGet {
+		t.Errorf("GetObject returned %v, expected an APIError", err)
+	}
+}
@@ -0,0 +1,34 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+//
+// SPDX-License-Identifier: MPL-2.0
+
+// Package client is the Go SDK for the Cisco Observability Platform. It authenticates against a tenant
+// (OAuth in a browser or with a device code, headless, service or agent principal, an access token or a
+// credential process, see the With* options) and manages knowledge store types and objects, refreshing
+// tokens and retrying transient failures along the way. The Terraform provider is built on top of it.
+//
+//	c, err := client.New("https://mytenant.observe.appdynamics.com", tenantID,
+//		client.WithServicePrincipal("/path/to/credentials.json"))
+//	if err != nil {
+//		...
+//	}
+//	if err := c.Login(ctx); err != nil {
+//		...
+//	}
+//	it := c.ListObjects(ctx, "fmm:namespace", tenantID, "TENANT", nil)
+//	for it.Next() {
+//		fmt.Println(it.Object().ID)
+//	}
+//
+// # Compatibility
+//
+// The package follows semantic versioning, see Version. Within a major version exported identifiers
+// are neither removed nor changed in an incompatible way; new functions, methods, options and struct
+// fields may be added in minor versions. Errors returned by failed API calls are always *APIError and
+// should be inspected with the Is* helpers rather than by matching the error text.
+package client
+
+// Version is the version of the client API, following semantic versioning
+const Version = "1.0.0"
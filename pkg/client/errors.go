@@ -0,0 +1,106 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+//
+// SPDX-License-Identifier: MPL-2.0
+
+package client
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/cisco-open/terraform-provider-observability/internal/api"
+)
+
+// login errors, to be detected with errors.Is
+var (
+	// ErrInvalidCredentials is returned by Client.Login when the platform rejects the username or password
+	ErrInvalidCredentials = api.ErrInvalidCredentials
+	// ErrMFARequired is returned by Client.Login when the user must complete multi-factor authentication,
+	// which is not possible with WithHeadless; use WithOAuth instead
+	ErrMFARequired = api.ErrMFARequired
+	// ErrTokenExpired is returned by Client.Login when the token given to WithAccessToken has expired
+	ErrTokenExpired = api.ErrTokenExpired
+)
+
+// APIError is returned when the platform responds with a non-2xx status. It carries the HTTP status,
+// the platform error code and message and the request ID to quote when contacting support.
+type APIError struct {
+	Method     string // HTTP method of the failed request
+	URL        string // URL of the failed request
+	StatusCode int    // HTTP status code, e.g., 404
+	Code       string // platform error code, if the payload carries one
+	Message    string // human readable error message, if the payload carries one
+	RequestID  string // request/trace ID reported by the platform, useful when contacting support
+	Retryable  bool   // whether the request may succeed if issued again
+}
+
+func (e *APIError) Error() string {
+	return (*api.APIError)(e).Error()
+}
+
+// wrappedAPIError is an error of the internal client wrapping an API error, e.g. a failed login request,
+// whose APIError is exposed as the package's own type
+type wrappedAPIError struct {
+	err    error
+	apiErr *APIError
+}
+
+func (e *wrappedAPIError) Error() string {
+	return e.err.Error()
+}
+
+func (e *wrappedAPIError) Unwrap() []error {
+	return []error{e.apiErr, e.err}
+}
+
+// convertError turns the API errors returned by the internal client into *APIError
+func convertError(err error) error {
+	var apiErr *api.APIError
+	if !errors.As(err, &apiErr) {
+		return err
+	}
+	converted := APIError(*apiErr)
+	if err == error(apiErr) { //nolint:errorlint // checking whether err is the API error itself, not wrapping it
+		return &converted
+	}
+	return &wrappedAPIError{err: err, apiErr: &converted}
+}
+
+// IsNotFound reports whether err is an APIError caused by a missing type or object
+func IsNotFound(err error) bool {
+	return hasStatus(err, http.StatusNotFound)
+}
+
+// IsConflict reports whether err is an APIError caused by a conflicting object (e.g., one that already exists)
+func IsConflict(err error) bool {
+	return hasStatus(err, http.StatusConflict)
+}
+
+// IsPreconditionFailed reports whether err is an APIError caused by a conditional request (see IfMatch)
+// for an object that was modified in the meantime
+func IsPreconditionFailed(err error) bool {
+	return hasStatus(err, http.StatusPreconditionFailed)
+}
+
+// IsUnauthorized reports whether err is an APIError caused by a missing, invalid or expired token
+func IsUnauthorized(err error) bool {
+	return hasStatus(err, http.StatusUnauthorized)
+}
+
+// IsForbidden reports whether err is an APIError caused by the principal lacking permissions
+func IsForbidden(err error) bool {
+	return hasStatus(err, http.StatusForbidden)
+}
+
+// IsRetryable reports whether err is an APIError for a transient failure that may succeed if retried
+func IsRetryable(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.Retryable
+}
+
+func hasStatus(err error, status int) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == status
+}
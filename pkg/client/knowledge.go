@@ -0,0 +1,297 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+//
+// SPDX-License-Identifier: MPL-2.0
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/cisco-open/terraform-provider-observability/internal/api"
+)
+
+// KnowledgeObject is an object as stored in the knowledge store, together with its identity and layer
+type KnowledgeObject struct {
+	ID             string
+	LayerID        string
+	LayerType      string
+	ObjectType     string
+	ObjectMimeType string
+	TargetObjectID *string
+	Data           json.RawMessage // the object payload, conforming to the type's JSON schema
+	CreatedAt      string
+	UpdatedAt      string
+}
+
+// ObjectIterator walks all objects of a type, fetching further pages from the knowledge store as needed,
+// see Client.ListObjects
+type ObjectIterator struct {
+	it *api.ObjectIterator
+}
+
+// Next advances the iterator to the next object, returning false when there are no more objects or
+// a request failed (see Err)
+func (it *ObjectIterator) Next() bool {
+	return it.it.Next()
+}
+
+// Object returns the current object; only valid after Next returned true
+func (it *ObjectIterator) Object() *KnowledgeObject {
+	obj := KnowledgeObject(*it.it.Object())
+	return &obj
+}
+
+// Total returns the total number of objects reported by the knowledge store with the last fetched page
+func (it *ObjectIterator) Total() int {
+	return it.it.Total()
+}
+
+// Err returns the error that stopped the iteration, if any
+func (it *ObjectIterator) Err() error {
+	return convertError(it.it.Err())
+}
+
+// All drains the iterator and returns all the remaining objects
+func (it *ObjectIterator) All() ([]KnowledgeObject, error) {
+	var objects []KnowledgeObject
+	for it.Next() {
+		objects = append(objects, *it.Object())
+	}
+	return objects, it.Err()
+}
+
+// TypeIterator walks all type definitions visible in a layer, see Client.ListTypes
+type TypeIterator struct {
+	it *api.TypeIterator
+}
+
+// Next advances the iterator to the next type definition, returning false when there are no more
+// types or a request failed (see Err)
+func (it *TypeIterator) Next() bool {
+	return it.it.Next()
+}
+
+// Type returns the JSON definition of the current type; only valid after Next returned true
+func (it *TypeIterator) Type() json.RawMessage {
+	return it.it.Type()
+}
+
+// Total returns the total number of types reported by the knowledge store with the last fetched page
+func (it *TypeIterator) Total() int {
+	return it.it.Total()
+}
+
+// Err returns the error that stopped the iteration, if any
+func (it *TypeIterator) Err() error {
+	return convertError(it.it.Err())
+}
+
+// All drains the iterator and returns all the remaining type definitions
+func (it *TypeIterator) All() ([]json.RawMessage, error) {
+	var types []json.RawMessage
+	for it.Next() {
+		types = append(types, it.Type())
+	}
+	return types, it.Err()
+}
+
+// FilterOperator is a comparison operator supported by knowledge store filter expressions
+type FilterOperator string
+
+// filter operators
+const (
+	OpEqual          FilterOperator = "eq"
+	OpNotEqual       FilterOperator = "ne"
+	OpGreaterThan    FilterOperator = "gt"
+	OpGreaterOrEqual FilterOperator = "ge"
+	OpLessThan       FilterOperator = "lt"
+	OpLessOrEqual    FilterOperator = "le"
+	OpContains       FilterOperator = "co"
+	OpStartsWith     FilterOperator = "sw"
+)
+
+// SortOrder is the direction in which listed objects are sorted
+type SortOrder string
+
+// sort orders
+const (
+	SortAscending  SortOrder = "asc"
+	SortDescending SortOrder = "desc"
+)
+
+// ObjectQuery narrows down, orders and projects the objects returned by Client.ListObjects. All the work is
+// done server-side by the knowledge store.
+//
+//	query := client.NewObjectQuery().
+//		Where("region", client.OpEqual, "us-east-2").
+//		SortBy("connectionName", client.SortAscending).
+//		Fields("connectionName", "region").
+//		Limit(10)
+type ObjectQuery struct {
+	q *api.ObjectQuery
+}
+
+// NewObjectQuery returns an empty query, matching every object of the type
+func NewObjectQuery() *ObjectQuery {
+	return &ObjectQuery{q: api.NewObjectQuery()}
+}
+
+// Where adds a condition on a field of the object data (e.g. "region" or "config.name"); all the
+// conditions of a query must match. String values are quoted, other values are used verbatim.
+func (q *ObjectQuery) Where(field string, op FilterOperator, value any) *ObjectQuery {
+	q.query().Where(field, api.FilterOperator(op), value)
+	return q
+}
+
+// Filter adds a raw knowledge store filter expression, e.g. `data.region eq "us-east-2"`
+func (q *ObjectQuery) Filter(expression string) *ObjectQuery {
+	q.query().Filter(expression)
+	return q
+}
+
+// SortBy orders the objects by a field of the object data
+func (q *ObjectQuery) SortBy(field string, order SortOrder) *ObjectQuery {
+	q.query().SortBy(field, api.SortOrder(order))
+	return q
+}
+
+// Limit caps the number of objects returned, 0 means no limit
+func (q *ObjectQuery) Limit(maxResults int) *ObjectQuery {
+	q.query().Limit(maxResults)
+	return q
+}
+
+// Fields restricts the object data returned to the given fields
+func (q *ObjectQuery) Fields(fields ...string) *ObjectQuery {
+	q.query().Fields(fields...)
+	return q
+}
+
+// query returns the internal query, so that the zero ObjectQuery is usable as well
+func (q *ObjectQuery) query() *api.ObjectQuery {
+	if q.q == nil {
+		q.q = api.NewObjectQuery()
+	}
+	return q.q
+}
+
+// RequestOption customizes a single knowledge store request, e.g. IfMatch
+type RequestOption struct {
+	apply api.RequestOption
+}
+
+// IfMatch makes an update or delete conditional on the object still being at the given version (ETag),
+// as returned by Client.GetObjectWithETag. An empty etag leaves the request unconditional.
+func IfMatch(etag string) RequestOption {
+	return RequestOption{apply: api.IfMatch(etag)}
+}
+
+// requestOptions returns the internal request options
+func requestOptions(opts []RequestOption) []api.RequestOption {
+	apiOpts := make([]api.RequestOption, 0, len(opts))
+	for _, opt := range opts {
+		if opt.apply != nil {
+			apiOpts = append(apiOpts, opt.apply)
+		}
+	}
+	return apiOpts
+}
+
+// CreateMergePatch computes the JSON merge patch (RFC 7396) that turns the original JSON object into
+// the modified one, to be sent with Client.PatchObject. An empty object ("{}") means there is nothing to change.
+func CreateMergePatch(original, modified []byte) ([]byte, error) {
+	return api.CreateMergePatch(original, modified)
+}
+
+// CreateManagedMergePatch is like CreateMergePatch, but only removes the fields of the original object which
+// are also in managed, the object as last applied by the caller, so that fields it never managed (e.g. the ones
+// of an imported object) are left untouched. A nil managed object removes nothing.
+func CreateManagedMergePatch(original, modified, managed []byte) ([]byte, error) {
+	return api.CreateManagedMergePatch(original, modified, managed)
+}
+
+// GetType returns the JSON definition of the type fullyQualifiedTypeName (e.g. fmm:namespace)
+func (c *Client) GetType(ctx context.Context, fullyQualifiedTypeName string) ([]byte, error) {
+	definition, err := c.ac.GetType(ctx, fullyQualifiedTypeName)
+	return definition, convertError(err)
+}
+
+// CreateType creates the type described by the JSON definition in body, in the given layer
+func (c *Client) CreateType(ctx context.Context, layerID, layerType string, body []byte) error {
+	return convertError(c.ac.CreateType(ctx, layerID, layerType, body))
+}
+
+// UpdateType replaces the definition of the type fullyQualifiedTypeName with the JSON definition in body
+func (c *Client) UpdateType(ctx context.Context, fullyQualifiedTypeName, layerID, layerType string, body []byte,
+	opts ...RequestOption) error {
+	return convertError(c.ac.UpdateType(ctx, fullyQualifiedTypeName, layerID, layerType, body, requestOptions(opts)...))
+}
+
+// DeleteType deletes the type fullyQualifiedTypeName
+func (c *Client) DeleteType(ctx context.Context, fullyQualifiedTypeName, layerID, layerType string, opts ...RequestOption) error {
+	return convertError(c.ac.DeleteType(ctx, fullyQualifiedTypeName, layerID, layerType, requestOptions(opts)...))
+}
+
+// ListTypes returns an iterator over the type definitions visible in the given layer
+func (c *Client) ListTypes(ctx context.Context, layerID, layerType string) *TypeIterator {
+	return &TypeIterator{it: c.ac.ListTypes(ctx, layerID, layerType)}
+}
+
+// CreateObject creates an object of type fullyQualifiedTypeName with the JSON payload in body, in the given
+// layer (layerType TENANT/SOLUTION/..., layerID e.g. the tenant ID). It returns the ID the knowledge store
+// assigned to the object and its version (ETag), either empty if the knowledge store did not report it.
+func (c *Client) CreateObject(ctx context.Context, fullyQualifiedTypeName, layerID, layerType string, body []byte) (
+	objectID, etag string, err error) {
+	objectID, etag, err = c.ac.CreateObject(ctx, fullyQualifiedTypeName, layerID, layerType, body)
+	return objectID, etag, convertError(err)
+}
+
+// GetObject returns the object objectID of type fullyQualifiedTypeName
+func (c *Client) GetObject(ctx context.Context, fullyQualifiedTypeName, objectID, layerID, layerType string) ([]byte, error) {
+	obj, err := c.ac.GetObject(ctx, fullyQualifiedTypeName, objectID, layerID, layerType)
+	return obj, convertError(err)
+}
+
+// GetObjectWithETag is like GetObject but also returns the object version (ETag), to be passed back with IfMatch
+func (c *Client) GetObjectWithETag(ctx context.Context, fullyQualifiedTypeName, objectID, layerID, layerType string) (
+	[]byte, string, error) {
+	obj, etag, err := c.ac.GetObjectWithETag(ctx, fullyQualifiedTypeName, objectID, layerID, layerType)
+	return obj, etag, convertError(err)
+}
+
+// UpdateObject replaces the payload of the object objectID with the JSON payload in body and returns its
+// new version (ETag), empty if the knowledge store did not report it
+func (c *Client) UpdateObject(ctx context.Context, fullyQualifiedTypeName, objectID, layerID, layerType string, body []byte,
+	opts ...RequestOption) (string, error) {
+	etag, err := c.ac.UpdateObject(ctx, fullyQualifiedTypeName, objectID, layerID, layerType, body, requestOptions(opts)...)
+	return etag, convertError(err)
+}
+
+// PatchObject applies the JSON merge patch in patch to the object objectID, leaving the fields not
+// mentioned in the patch untouched, see CreateMergePatch. It returns the new version (ETag) of the object,
+// empty if the knowledge store did not report it.
+func (c *Client) PatchObject(ctx context.Context, fullyQualifiedTypeName, objectID, layerID, layerType string, patch []byte,
+	opts ...RequestOption) (string, error) {
+	etag, err := c.ac.PatchObject(ctx, fullyQualifiedTypeName, objectID, layerID, layerType, patch, requestOptions(opts)...)
+	return etag, convertError(err)
+}
+
+// DeleteObject deletes the object objectID
+func (c *Client) DeleteObject(ctx context.Context, fullyQualifiedTypeName, objectID, layerID, layerType string,
+	opts ...RequestOption) error {
+	return convertError(c.ac.DeleteObject(ctx, fullyQualifiedTypeName, objectID, layerID, layerType, requestOptions(opts)...))
+}
+
+// ListObjects returns an iterator over the objects of fullyQualifiedTypeName visible in the given layer,
+// optionally narrowed down by query (nil lists all objects)
+func (c *Client) ListObjects(ctx context.Context, fullyQualifiedTypeName, layerID, layerType string,
+	query *ObjectQuery) *ObjectIterator {
+	var apiQuery *api.ObjectQuery
+	if query != nil {
+		apiQuery = query.query()
+	}
+	return &ObjectIterator{it: c.ac.ListObjects(ctx, fullyQualifiedTypeName, layerID, layerType, apiQuery)}
+}
@@ -0,0 +1,33 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+//
+// SPDX-License-Identifier: MPL-2.0
+
+package client
+
+import "github.com/cisco-open/terraform-provider-observability/internal/api"
+
+// FsocProfile holds the connection settings of an fsoc profile, including the tokens fsoc cached for it
+type FsocProfile struct {
+	Name         string
+	AuthMethod   string
+	URL          string
+	Server       string // host name used by older fsoc versions instead of url
+	Tenant       string
+	User         string
+	SecretFile   string
+	Token        string
+	RefreshToken string
+}
+
+// LoadFsocProfile reads the named profile from the fsoc config file ($FSOC_CONFIG or ~/.fsoc);
+// an empty name selects fsoc's current profile
+func LoadFsocProfile(name string) (*FsocProfile, error) {
+	profile, err := api.LoadFsocProfile(name)
+	if err != nil {
+		return nil, err
+	}
+	converted := FsocProfile(*profile)
+	return &converted, nil
+}
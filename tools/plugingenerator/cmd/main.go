@@ -7,13 +7,13 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"log"
-	"net/http"
 	"os/user"
 	"path/filepath"
 
-	"github.com/cisco-open/terraform-provider-observability/internal/api"
+	"github.com/cisco-open/terraform-provider-observability/pkg/client"
 	"github.com/cisco-open/terraform-provider-observability/tools/plugingenerator"
 )
 
@@ -25,7 +25,6 @@ var (
 )
 
 const (
-	servicePrincipal         = "service-principal"
 	registeredObjectTypeJSON = "object_types.json"
 )
 
@@ -38,6 +37,8 @@ func main() {
 	flag.StringVar(&rootOfTheRepo, "repo-root", "", "path to the root of the repo")
 	flag.Parse()
 
+	ctx := context.Background()
+
 	// Get current user information
 	currentUser, err := user.Current()
 	if err != nil {
@@ -47,21 +48,18 @@ func main() {
 	// Construct the full path
 	secretsFilePath := filepath.Join(currentUser.HomeDir, secretsFileName)
 
-	appdClient := &api.AppdClient{
-		AuthMethod: servicePrincipal,
-		Tenant:     tenant,
-		URL:        url,
-		SecretFile: secretsFilePath,
-		APIClient:  http.DefaultClient,
+	observabilityClient, err := client.New(url, tenant, client.WithServicePrincipal(secretsFilePath))
+	if err != nil {
+		log.Fatal(err.Error())
 	}
 
 	// there is no point in going forward, just exit
-	err = appdClient.Login()
+	err = observabilityClient.Login(ctx)
 	if err != nil {
 		log.Fatal(err.Error())
 	}
 
-	schemaTypesStore, err := plugingenerator.PopulateSchemaTypeStore(appdClient)
+	schemaTypesStore, err := plugingenerator.PopulateSchemaTypeStore(ctx, observabilityClient)
 	if err != nil {
 		log.Fatal(err.Error())
 	}
@@ -8,6 +8,7 @@ package plugingenerator
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"go/format"
@@ -17,7 +18,7 @@ import (
 	"text/template"
 	"unicode"
 
-	"github.com/cisco-open/terraform-provider-observability/internal/api"
+	"github.com/cisco-open/terraform-provider-observability/pkg/client"
 	"github.com/mitchellh/mapstructure"
 	"golang.org/x/sync/errgroup"
 )
@@ -72,7 +73,7 @@ type Property struct {
 // PopulateSchemaTypeStore reads object_types.json file containing registered object types,
 // retrieves schemas for each type from the API client, and populates a SchemaTypeStore.
 // It returns the populated SchemaTypeStore or an error if any operation fails.
-func PopulateSchemaTypeStore(appdClient *api.AppdClient) (SchemaTypeStore, error) {
+func PopulateSchemaTypeStore(ctx context.Context, observabilityClient *client.Client) (SchemaTypeStore, error) {
 	// read the file
 	dataBytes, err := os.ReadFile(registeredObjectTypeJSON)
 	if err != nil {
@@ -88,12 +89,12 @@ func PopulateSchemaTypeStore(appdClient *api.AppdClient) (SchemaTypeStore, error
 
 	// store the schemas key: fullyQualifiedTypeName: the actual schema
 
-	var g errgroup.Group
+	g, gctx := errgroup.WithContext(ctx)
 	var m sync.Mutex
 	schemaTypesStore := make(SchemaTypeStore)
 	for _, fqtn := range schemaTypes.FullyQualifiedTypeNames {
 		g.Go(func() error {
-			schema, err := appdClient.GetType(fqtn)
+			schema, err := observabilityClient.GetType(gctx, fqtn)
 			if err != nil {
 				return fmt.Errorf("error during get type api call: %w", err)
 			}